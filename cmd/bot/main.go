@@ -12,10 +12,17 @@ import (
 
 	"github.com/kamilkurek/margonem-bot/internal/behavior"
 	"github.com/kamilkurek/margonem-bot/internal/browser"
+	"github.com/kamilkurek/margonem-bot/internal/chat"
 	"github.com/kamilkurek/margonem-bot/internal/combat"
 	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/events"
 	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/heatmap"
+	"github.com/kamilkurek/margonem-bot/internal/loot"
 	"github.com/kamilkurek/margonem-bot/internal/navigation"
+	"github.com/kamilkurek/margonem-bot/internal/players"
+	"github.com/kamilkurek/margonem-bot/internal/quest"
+	"github.com/kamilkurek/margonem-bot/internal/town"
 	"github.com/sirupsen/logrus"
 )
 
@@ -45,6 +52,22 @@ func main() {
 	log.Info("Starting Margonem Bot...")
 	log.WithField("version", version).Info("Bot version")
 
+	// Subcommands work offline on the collected data and only need the
+	// runtime settings
+	if flag.Arg(0) == "stats" {
+		runtimeCfg, err := config.LoadRuntime(*configPath)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load configuration")
+		}
+		if runtimeCfg.Debug {
+			log.SetLevel(logrus.DebugLevel)
+		}
+		if err := runStats(runtimeCfg, flag.Args()[1:], log); err != nil {
+			log.WithError(err).Fatal("Stats failed")
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load(*configPath)
 	if err != nil {
@@ -104,17 +127,37 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 	defer browserCtrl.Stop()
 
 	// Initialize components
-	gameClient := game.NewClient(browserCtrl, log)
+	bus := events.NewBus()
 	stateMgr := game.NewStateManager()
+	gameClient := game.NewClient(browserCtrl, stateMgr, log)
+	// Walks run at the hunting or the idle poll rate
+	gameClient.SetPollInterval(max(cfg.Timing.Poll(), cfg.Timing.IdlePoll()))
 	combatEngine := combat.NewEngine(gameClient, cfg, log)
 	navigator := navigation.NewNavigator(gameClient, cfg, log)
+	collector := loot.NewCollector(gameClient, cfg, log)
+	townTrip := town.NewTrip(gameClient, navigator, cfg, log)
+	questTracker := quest.NewTracker(gameClient, navigator, cfg, log)
+	combatEngine.SetTargetPriority(questTracker.TargetPriority())
+	playerWatch := players.NewWatch(cfg, log)
+
+	heat, err := heatmap.Load(cfg.Runtime.HeatmapFile)
+	if err != nil {
+		return fmt.Errorf("failed to load heatmap: %w", err)
+	}
+	navigator.UseHeatmap(heat)
+	unsticker := navigation.NewUnsticker(browserCtrl, gameClient, navigator, bus, cfg, log)
+	defer func() {
+		if err := heat.Save(); err != nil {
+			log.WithError(err).Warn("Failed to save heatmap")
+		}
+	}()
 
 	// State machine
 	phase := game.PhaseLogin
 
 	// Login
 	log.Info("Phase: LOGIN")
-	if err := performLogin(browserCtrl, cfg, log); err != nil {
+	if err := performLogin(browserCtrl, gameClient, cfg, log); err != nil {
 		return fmt.Errorf("login failed: %w", err)
 	}
 
@@ -128,11 +171,24 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 	}
 
 	// Start state polling
-	go pollGameState(ctx, gameClient, stateMgr, log)
+	go pollGameState(ctx, gameClient, stateMgr, heat, bus, cfg, log)
+
+	// Watch chat for game masters and players talking to us
+	if cfg.Chat.Enabled {
+		reactor := chat.NewReactor(browserCtrl, stateMgr, bus, cfg, log)
+		go reactor.Run(ctx)
+		go chat.NewWatcher(gameClient, stateMgr, bus, cfg, log).Run(ctx)
+	}
 
 	// Give it a moment to gather initial state
 	time.Sleep(2 * time.Second)
 
+	if inv, err := gameClient.GetInventory(); err != nil {
+		log.WithError(err).Warn("Failed to read inventory")
+	} else {
+		stateMgr.UpdateInventory(inv)
+	}
+
 	// Auto-detect mode: just detect current location, skip navigation
 	if cfg.Runtime.AutoDetectMode {
 		log.Info("AUTO-DETECT MODE: Bot will hunt at current location")
@@ -166,19 +222,34 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 	phase = game.PhaseHunt
 	stateMgr.SetPhase(phase)
 
-	ticker := time.NewTicker(2 * time.Second) // Combat tick every 2 seconds
-	defer ticker.Stop()
+	// Combat tick, woken early by state changes from the poller
+	ticks := combat.Triggers(ctx, cfg.Timing.CombatTick(), bus)
 
 	lastPatrol := time.Now()
-	patrolInterval := 30 * time.Second
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info("Context cancelled, stopping bot")
+			logStats(stateMgr, log)
 			return nil
 
-		case <-ticker.C:
+		case <-ticks:
+			// Stay idle while paused (chat rules, nearby players...)
+			if paused, reason := stateMgr.Paused(); paused {
+				if phase != game.PhasePaused {
+					log.WithField("reason", reason).Warn("Phase: PAUSED")
+					phase = game.PhasePaused
+					stateMgr.SetPhase(phase)
+				}
+				continue
+			}
+			if phase == game.PhasePaused {
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+			}
+
 			hero := stateMgr.GetHero()
 
 			// Check if dead
@@ -221,7 +292,7 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 			}
 
 			// Check connection
-			if connected, err := gameClient.IsConnected(); err != nil || !connected {
+			if !stateMgr.IsConnected() {
 				log.Warn("Phase: DISCONNECTED")
 				phase = game.PhaseDisconnected
 				stateMgr.SetPhase(phase)
@@ -236,16 +307,104 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 				continue
 			}
 
+			// Fight battles turn by turn with the skill rotation
+			if stateMgr.InBattle() {
+				log.Info("Phase: BATTLE")
+				phase = game.PhaseBattle
+				stateMgr.SetPhase(phase)
+
+				if err := combatEngine.Fight(ctx, stateMgr); err != nil {
+					log.WithError(err).Warn("Battle failed")
+				}
+
+				// Pick up drops after a won battle and check quest progress
+				if kills := combatEngine.TakeKills(); len(kills) > 0 {
+					if err := collector.Collect(stateMgr); err != nil {
+						log.WithError(err).WithField("mobs", kills).Warn("Loot collection failed")
+					}
+
+					for _, name := range kills {
+						questTracker.RecordKill(name)
+					}
+					if questTracker.Complete() {
+						log.Info("Phase: QUEST")
+						phase = game.PhaseQuest
+						stateMgr.SetPhase(phase)
+
+						more, err := questTracker.TurnIn(stateMgr)
+						if err != nil {
+							log.WithError(err).Error("Quest turn-in failed")
+						}
+						if !more {
+							log.Info("No quests left, hunting on")
+						}
+						combatEngine.SetTargetPriority(questTracker.TargetPriority())
+						combatEngine.Reset()
+					}
+				}
+
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+				continue
+			}
+
+			// Take the active quest: the first one when tracking starts,
+			// later ones again when taking them after a turn-in failed
+			if questTracker.NeedsAccept() && !hero.InCombat {
+				log.Info("Phase: QUEST")
+				phase = game.PhaseQuest
+				stateMgr.SetPhase(phase)
+
+				if err := questTracker.Accept(stateMgr); err != nil {
+					log.WithError(err).Error("Taking quest failed")
+				}
+				combatEngine.SetTargetPriority(questTracker.TargetPriority())
+				combatEngine.Reset()
+
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+				continue
+			}
+
+			// Keep an eye on other players and rotate hunting grounds
+			rotate, reason := navigator.ShouldRotate(stateMgr)
+			if crowded := playerWatch.Check(stateMgr); crowded {
+				rotate, reason = true, "hunting ground crowded"
+			}
+			if rotate && !hero.InCombat {
+				log.WithField("reason", reason).Info("Phase: NAVIGATE")
+				phase = game.PhaseNavigate
+				stateMgr.SetPhase(phase)
+
+				if err := navigator.SwitchHuntingGround(stateMgr); err != nil {
+					log.WithError(err).Warn("Failed to switch hunting ground")
+				}
+
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+				combatEngine.Reset()
+				continue
+			}
+			if paused, _ := stateMgr.Paused(); paused {
+				continue
+			}
+
 			// Check if stuck
-			if stateMgr.IsStuck(10, 30*time.Second) {
+			if stateMgr.IsStuck(cfg.Timing.StuckThreshold, cfg.Timing.Stuck()) {
 				log.Warn("Character appears stuck, attempting recovery")
-				if err := navigator.PatrolArea(stateMgr); err != nil {
+				if err := unsticker.Unstick(stateMgr); err != nil {
 					log.WithError(err).Warn("Failed to recover from stuck state")
 				}
+				combatEngine.Reset()
+				continue
 			}
 
 			// Periodic patrol to find mobs
-			if time.Since(lastPatrol) > patrolInterval {
+			navigator.ObserveSpawns(stateMgr)
+			if time.Since(lastPatrol) > cfg.Timing.Patrol() {
 				mobs := stateMgr.GetMobs()
 				if len(mobs) == 0 {
 					log.Debug("No mobs nearby, patrolling")
@@ -256,6 +415,42 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 				lastPatrol = time.Now()
 			}
 
+			// Sell junk and restock when bags are full or consumables run low
+			if needed, reason := townTrip.Needed(stateMgr); needed && !hero.InCombat {
+				log.WithField("reason", reason).Info("Phase: TOWN")
+				phase = game.PhaseTown
+				stateMgr.SetPhase(phase)
+
+				if err := townTrip.Run(stateMgr); err != nil {
+					log.WithError(err).Error("Town trip failed")
+				}
+
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+				combatEngine.Reset()
+				continue
+			}
+
+			// Drink elixirs and potions; some rules (poisoned at low HP) call for a retreat
+			retreat := combatEngine.UseConsumables(stateMgr)
+
+			// Rest when HP is low instead of fighting on
+			if retreat || combatEngine.NeedsRest(&hero) {
+				log.Info("Phase: RESTING")
+				phase = game.PhaseRest
+				stateMgr.SetPhase(phase)
+
+				if err := combatEngine.Rest(ctx, stateMgr); err != nil {
+					log.WithError(err).Warn("Rest failed")
+				}
+
+				log.Info("Phase: HUNT")
+				phase = game.PhaseHunt
+				stateMgr.SetPhase(phase)
+				continue
+			}
+
 			// Combat tick
 			if err := combatEngine.Tick(stateMgr); err != nil {
 				log.WithError(err).Warn("Combat tick failed")
@@ -271,8 +466,43 @@ func run(ctx context.Context, cfg *config.Config, log *logrus.Logger) error {
 	}
 }
 
+// logStats prints a summary of the session
+func logStats(stateMgr *game.StateManager, log *logrus.Logger) {
+	stats := stateMgr.GetStats()
+	log.WithFields(logrus.Fields{
+		"uptime":   stats.Uptime().Round(time.Second),
+		"rests":    stats.Rests,
+		"restTime": stats.RestTime.Round(time.Second),
+		"kills":    stats.Kills,
+		"looted":   stats.ItemsLooted,
+		"trips":    stats.TownTrips,
+		"earned":   stats.GoldEarned,
+		"spent":    stats.GoldSpent,
+		"stuck":    stats.Stuck,
+		"unstick":  stats.Unstick,
+	}).Info("Session stats")
+
+	exp := stateMgr.ExpReport()
+	log.WithFields(logrus.Fields{
+		"level":   exp.Level,
+		"exp":     exp.Gained,
+		"perHour": exp.PerHour,
+		"toLevel": exp.ToLevel,
+		"eta":     exp.ETA.Round(time.Minute),
+	}).Info("Exp progress")
+
+	for name, m := range stats.MobExp {
+		log.WithFields(logrus.Fields{
+			"mob":     name,
+			"kills":   m.Kills,
+			"exp":     m.Exp,
+			"perKill": m.PerKill(),
+		}).Info("Exp per mob")
+	}
+}
+
 // performLogin logs into the game
-func performLogin(browserCtrl *browser.Controller, cfg *config.Config, log *logrus.Logger) error {
+func performLogin(browserCtrl *browser.Controller, gameClient *game.Client, cfg *config.Config, log *logrus.Logger) error {
 	log.Info("Navigating to game...")
 
 	if err := browserCtrl.Navigate(cfg.Account.StartURL); err != nil {
@@ -290,47 +520,15 @@ func performLogin(browserCtrl *browser.Controller, cfg *config.Config, log *logr
 	// This is a simplified version - actual Margonem login might be different
 	log.Info("Attempting login...")
 
-	// Check if already logged in by looking for game canvas
-	script := `
-	(function() {
-		return window.hero !== undefined && window.hero !== null;
-	})()
-	`
-
-	var alreadyLoggedIn bool
-	if err := browserCtrl.Eval(script, &alreadyLoggedIn); err == nil && alreadyLoggedIn {
+	// Check if already logged in by looking for the hero
+	if loggedIn, err := gameClient.LoggedIn(); err == nil && loggedIn {
 		log.Info("Already logged in (using saved session)")
 		return nil
 	}
 
 	// Perform login
 	// NOTE: This is a placeholder - actual Margonem login flow will need to be adapted
-	loginScript := fmt.Sprintf(`
-	(function() {
-		try {
-			// Try to fill login form
-			let userField = document.querySelector('input[name="login"], input[name="username"], #login, #username');
-			let passField = document.querySelector('input[name="password"], input[type="password"], #password');
-			let loginBtn = document.querySelector('button[type="submit"], input[type="submit"], .login-button, #login-button');
-
-			if (userField && passField) {
-				userField.value = '%s';
-				passField.value = '%s';
-				if (loginBtn) {
-					loginBtn.click();
-					return true;
-				}
-			}
-			return false;
-		} catch(e) {
-			console.error('Login error:', e);
-			return false;
-		}
-	})()
-	`, cfg.Account.Username, cfg.Account.Password)
-
-	var loginSuccess bool
-	if err := browserCtrl.Eval(loginScript, &loginSuccess); err != nil {
+	if submitted, err := gameClient.SubmitLogin(cfg.Account.Username, cfg.Account.Password); err != nil || !submitted {
 		log.Warn("Automated login failed, manual intervention may be required")
 	}
 
@@ -349,41 +547,82 @@ func performLogin(browserCtrl *browser.Controller, cfg *config.Config, log *logr
 }
 
 // pollGameState continuously updates game state
-func pollGameState(ctx context.Context, gameClient *game.Client, stateMgr *game.StateManager, log *logrus.Logger) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+func pollGameState(ctx context.Context, gameClient *game.Client, stateMgr *game.StateManager, heat *heatmap.Heatmap, bus *events.Bus, cfg *config.Config, log *logrus.Logger) {
+	timer := time.NewTimer(cfg.Timing.Poll())
+	defer timer.Stop()
+
+	lastSave := time.Now()
+	var prev *game.Snapshot
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			// Get hero state
-			hero, err := gameClient.GetHeroState()
+		case <-timer.C:
+			// Read the whole world in one evaluation
+			snap, err := gameClient.Snapshot()
+			timer.Reset(pollInterval(snap, stateMgr, &cfg.Timing))
+
+			// Hold the bot while the adapter scripts miss required fields
+			// and let it go as soon as they come back
+			if broken := gameClient.HealthCheck(); broken != nil {
+				stateMgr.Hold(broken.Error())
+			} else {
+				stateMgr.Release()
+			}
+
 			if err != nil {
-				log.WithError(err).Debug("Failed to get hero state")
+				log.WithError(err).Debug("Failed to take game snapshot")
 				continue
 			}
-			stateMgr.UpdateHero(hero)
 
-			// Get mobs
-			mobs, err := gameClient.GetMobs()
-			if err != nil {
-				log.WithError(err).Debug("Failed to get mobs")
+			players.MarkContested(snap.Mobs, snap.Players, &cfg.Players)
+			stateMgr.ApplySnapshot(snap)
+			if snap.Hero == nil {
+				log.Debug("Hero not loaded")
 				continue
 			}
-			stateMgr.UpdateMobs(mobs)
 
-			// Check connection
-			connected, err := gameClient.IsConnected()
-			if err != nil {
-				connected = false
+			// Wake the combat engine on anything it should react to
+			mobs, battle, hero := snap.Changes(prev)
+			if mobs {
+				bus.Publish(events.MobsChanged, snap)
+			}
+			if battle {
+				bus.Publish(events.BattleChanged, snap)
+			}
+			if hero {
+				bus.Publish(events.HeroChanged, snap)
+			}
+			prev = snap
+
+			// Learn where mobs spawn and how long they take to come back
+			heat.Observe(snap.Hero.MapID, snap.Mobs)
+			if time.Since(lastSave) > time.Minute {
+				if err := heat.Save(); err != nil {
+					log.WithError(err).Warn("Failed to save heatmap")
+				}
+				lastSave = time.Now()
 			}
-			stateMgr.UpdateConnection(connected)
 		}
 	}
 }
 
+// pollInterval polls faster during a battle and slower while there is
+// nothing to react to: paused, or no mobs in sight and nowhere to walk
+func pollInterval(snap *game.Snapshot, stateMgr *game.StateManager, timing *config.TimingConfig) time.Duration {
+	if snap != nil && snap.InBattle {
+		return timing.BattlePoll()
+	}
+	if paused, _ := stateMgr.Paused(); paused || snap == nil || snap.Hero == nil {
+		return timing.IdlePoll()
+	}
+	if _, walking := stateMgr.GetMoveGoal(); !walking && snap.LiveMobs() == 0 {
+		return timing.IdlePoll()
+	}
+	return timing.Poll()
+}
+
 // handleDeath handles character death and respawn
 func handleDeath(gameClient *game.Client, navigator *navigation.Navigator, stateMgr *game.StateManager, log *logrus.Logger) error {
 	log.Info("Handling death...")
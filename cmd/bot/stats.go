@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/heatmap"
+	"github.com/sirupsen/logrus"
+)
+
+// unsafeFileChars matches what may not appear in a map's file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runStats renders the learned spawn heatmap of every map (or one map)
+// as PNG files and prints the respawn estimates per mob
+func runStats(cfg *config.RuntimeConfig, args []string, log *logrus.Logger) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	outDir := fs.String("out", "./heatmaps", "Directory for the rendered PNG files")
+	mapID := fs.String("map", "", "Only render this map")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	heat, err := heatmap.Load(cfg.HeatmapFile)
+	if err != nil {
+		return err
+	}
+
+	maps := heat.MapIDs()
+	if *mapID != "" {
+		maps = []string{*mapID}
+	}
+	if len(maps) == 0 {
+		log.WithField("file", cfg.HeatmapFile).Warn("No observations recorded yet")
+		return nil
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, id := range maps {
+		path := filepath.Join(*outDir, fmt.Sprintf("map_%s.png", mapFileName(id)))
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		err = heat.Render(id, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to render map %s: %w", id, err)
+		}
+		log.WithFields(logrus.Fields{
+			"map":  id,
+			"file": path,
+		}).Info("Rendered heatmap")
+
+		for _, name := range heat.MobNames(id) {
+			fields := logrus.Fields{"map": id, "mob": name}
+			if respawn, ok := heat.RespawnEstimate(id, name); ok {
+				fields["respawn"] = respawn.Round(time.Second)
+			} else {
+				fields["respawn"] = "unknown"
+			}
+			log.WithFields(fields).Info("Mob spawn")
+		}
+	}
+
+	return nil
+}
+
+// mapFileName turns a map ID into something safe to use in a file name
+func mapFileName(id string) string {
+	return unsafeFileChars.ReplaceAllString(id, "_")
+}
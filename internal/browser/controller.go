@@ -2,9 +2,12 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 	"github.com/sirupsen/logrus"
 )
@@ -15,6 +18,9 @@ type Controller struct {
 	cancel context.CancelFunc
 	log    *logrus.Logger
 	debug  bool
+	
+	mu     sync.Mutex
+	global runtime.RemoteObjectID // page global for Call, "" until resolved
 }
 
 // New creates a new browser controller
@@ -136,6 +142,18 @@ func (c *Controller) ClickAt(x, y float64) error {
 	)
 }
 
+// DoubleClickAt double-clicks at specific coordinates
+func (c *Controller) DoubleClickAt(x, y float64) error {
+	c.log.WithFields(logrus.Fields{
+		"x": x,
+		"y": y,
+	}).Debug("Double-clicking at coordinates...")
+	
+	return chromedp.Run(c.ctx,
+		chromedp.MouseClickXY(x, y, chromedp.ClickCount(2)),
+	)
+}
+
 // Eval evaluates JavaScript and returns the result
 func (c *Controller) Eval(script string, res interface{}) error {
 	return chromedp.Run(c.ctx,
@@ -143,6 +161,67 @@ func (c *Controller) Eval(script string, res interface{}) error {
 	)
 }
 
+// Call invokes a JavaScript function declaration with the given arguments,
+// which are passed as JSON values rather than spliced into the source.
+// It runs on the page's global object, resolved once per page load, so a
+// call normally costs one round trip.
+func (c *Controller) Call(fn string, res interface{}, args ...interface{}) error {
+	return chromedp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		global, cached, err := c.globalObject(ctx)
+		if err != nil {
+			return err
+		}
+		
+		err = callOn(ctx, global, fn, res, args)
+		var exception *runtime.ExceptionDetails
+		if err == nil || errors.As(err, &exception) || !cached {
+			return err
+		}
+		
+		// The page was reloaded since, which drops the global; resolve it again
+		c.forgetGlobal(global)
+		if global, _, err = c.globalObject(ctx); err != nil {
+			return err
+		}
+		return callOn(ctx, global, fn, res, args)
+	}))
+}
+
+// callOn runs a function declaration on a remote object
+func callOn(ctx context.Context, obj runtime.RemoteObjectID, fn string, res interface{}, args []interface{}) error {
+	onObj := func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+		return p.WithObjectID(obj)
+	}
+	return chromedp.CallFunctionOn(fn, res, onObj, args...).Do(ctx)
+}
+
+// globalObject returns the page's global object, from the cache when it
+// was resolved before
+func (c *Controller) globalObject(ctx context.Context) (id runtime.RemoteObjectID, cached bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if c.global != "" {
+		return c.global, true, nil
+	}
+	
+	var global *runtime.RemoteObject
+	if err := chromedp.Evaluate("globalThis", &global).Do(ctx); err != nil {
+		return "", false, err
+	}
+	c.global = global.ObjectID
+	return c.global, false, nil
+}
+
+// forgetGlobal drops a cached global object that no longer resolves
+func (c *Controller) forgetGlobal(id runtime.RemoteObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.global == id {
+		c.global = ""
+	}
+}
+
 // EvalWithTimeout evaluates JavaScript with timeout
 func (c *Controller) EvalWithTimeout(script string, res interface{}, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(c.ctx, timeout)
@@ -0,0 +1,129 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/browser"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/events"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/notify"
+	"github.com/sirupsen/logrus"
+)
+
+// unsafeFileChars matches what may not appear in a screenshot's file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// rule is a ChatRule with its patterns compiled
+type rule struct {
+	cfg     config.ChatRule
+	author  *regexp.Regexp
+	text    *regexp.Regexp
+	channel *regexp.Regexp
+}
+
+// matches checks a message against every pattern of the rule
+func (r *rule) matches(msg game.ChatMessage) bool {
+	return r.author.MatchString(msg.Author) &&
+		r.text.MatchString(msg.Text) &&
+		r.channel.MatchString(msg.Channel)
+}
+
+// Reactor applies the configured chat rules to chat events
+type Reactor struct {
+	browser  *browser.Controller
+	stateMgr *game.StateManager
+	cfg      *config.Config
+	log      *logrus.Logger
+	rules    []*rule
+	events   <-chan events.Event
+}
+
+// NewReactor creates a new chat reactor. Patterns are validated when the
+// config is loaded, so compiling them here can't fail.
+func NewReactor(browser *browser.Controller, stateMgr *game.StateManager, bus *events.Bus, cfg *config.Config, log *logrus.Logger) *Reactor {
+	rules := make([]*rule, 0, len(cfg.Chat.Rules))
+	for _, rc := range cfg.Chat.Rules {
+		rules = append(rules, &rule{
+			cfg:     rc,
+			author:  regexp.MustCompile(rc.Author),
+			text:    regexp.MustCompile(rc.Text),
+			channel: regexp.MustCompile(rc.Channel),
+		})
+	}
+
+	return &Reactor{
+		browser:  browser,
+		stateMgr: stateMgr,
+		cfg:      cfg,
+		log:      log,
+		rules:    rules,
+		events:   bus.Subscribe(events.ChatMessage, 64),
+	}
+}
+
+// Run handles chat events until the context is cancelled
+func (r *Reactor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-r.events:
+			msg, ok := e.Data.(game.ChatMessage)
+			if !ok {
+				continue
+			}
+
+			r.log.WithFields(logrus.Fields{
+				"channel": msg.Channel,
+				"author":  msg.Author,
+			}).Debugf("Chat: %s", msg.Text)
+
+			for _, rl := range r.rules {
+				if rl.matches(msg) {
+					r.react(rl.cfg, msg)
+				}
+			}
+		}
+	}
+}
+
+// react runs the actions of a matched rule
+func (r *Reactor) react(rc config.ChatRule, msg game.ChatMessage) {
+	fields := logrus.Fields{
+		"rule":    rc.Name,
+		"channel": msg.Channel,
+		"author":  msg.Author,
+		"text":    msg.Text,
+	}
+
+	for _, action := range rc.Actions {
+		switch action {
+		case "log":
+			r.log.WithFields(fields).Warn("Chat rule matched")
+
+		case "pause":
+			r.log.WithFields(fields).Warn("Chat rule matched, pausing bot")
+			r.stateMgr.Pause(time.Duration(rc.PauseSeconds)*time.Second, "chat rule "+rc.Name)
+
+		case "screenshot":
+			ruleName := unsafeFileChars.ReplaceAllString(rc.Name, "_")
+			name := fmt.Sprintf("chat-%s-%s.png", ruleName, time.Now().Format("20060102-150405"))
+			path := filepath.Join(r.cfg.Runtime.ScreenshotDir, name)
+			if err := r.browser.Screenshot(path); err != nil {
+				r.log.WithError(err).Warn("Failed to take chat screenshot")
+			}
+
+		case "notify":
+			title := fmt.Sprintf("Margonem bot: %s", rc.Name)
+			body := fmt.Sprintf("[%s] %s: %s", msg.Channel, msg.Author, msg.Text)
+			if err := notify.Send(title, body); err != nil {
+				r.log.WithError(err).Warn("Failed to send notification")
+			}
+		}
+	}
+}
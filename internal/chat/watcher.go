@@ -0,0 +1,99 @@
+package chat
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/events"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+// Watcher polls the game chat and publishes new messages on the event bus
+type Watcher struct {
+	gameClient *game.Client
+	stateMgr   *game.StateManager
+	bus        *events.Bus
+	cfg        *config.Config
+	log        *logrus.Logger
+	since      time.Time       // time stamp of the newest message seen, in page time
+	seen       map[string]bool // messages stamped since, others may still share it
+}
+
+// NewWatcher creates a new chat watcher
+func NewWatcher(gameClient *game.Client, stateMgr *game.StateManager, bus *events.Bus, cfg *config.Config, log *logrus.Logger) *Watcher {
+	return &Watcher{
+		gameClient: gameClient,
+		stateMgr:   stateMgr,
+		bus:        bus,
+		cfg:        cfg,
+		log:        log,
+		seen:       make(map[string]bool),
+	}
+}
+
+// Run polls the chat until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(w.cfg.Chat.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	// Only react to what is said from now on: the first successful read
+	// just sets the baseline, in the page's own time stamps
+	baseline := true
+
+	for {
+		messages, err := w.gameClient.GetChatMessages(w.since)
+		if err != nil {
+			w.log.WithError(err).Debug("Failed to read chat")
+		} else {
+			for _, msg := range w.fresh(messages) {
+				if !baseline && !w.own(msg) {
+					w.bus.Publish(events.ChatMessage, msg)
+				}
+			}
+			baseline = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fresh returns the messages not seen before. The game stamps messages in
+// seconds, so the ones sharing the newest time stamp are remembered to let
+// later messages with the same stamp through exactly once.
+func (w *Watcher) fresh(messages []game.ChatMessage) []game.ChatMessage {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Time.Before(messages[j].Time)
+	})
+
+	var fresh []game.ChatMessage
+	for _, msg := range messages {
+		if msg.Time.Before(w.since) {
+			continue
+		}
+		key := msg.Channel + "\x00" + msg.Author + "\x00" + msg.Text
+		if msg.Time.After(w.since) {
+			w.since = msg.Time
+			w.seen = make(map[string]bool)
+		} else if w.seen[key] {
+			continue
+		}
+		w.seen[key] = true
+		fresh = append(fresh, msg)
+	}
+	return fresh
+}
+
+// own reports whether the hero wrote the message, so rules never react to
+// the bot itself
+func (w *Watcher) own(msg game.ChatMessage) bool {
+	hero := w.stateMgr.GetHero()
+	return hero.Nick != "" && strings.EqualFold(msg.Author, hero.Nick)
+}
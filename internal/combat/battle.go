@@ -0,0 +1,218 @@
+package combat
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxBattleTime = 3 * time.Minute // hand a battle to auto-fight after this long
+	turnTimeout   = 5 * time.Second // how long the game gets to end our turn after acting
+)
+
+// Fight drives a battle turn by turn until it ends, using the skill
+// rotation on each of the hero's turns. When skills can't be used it falls
+// back to the game's auto-fight if configured, the default attack otherwise.
+// Auto-fight is tried once per battle; after a failure the hero fights on.
+func (e *Engine) Fight(ctx context.Context, stateMgr *game.StateManager) error {
+	start := time.Now()
+	auto := false                     // toggled on once at most, the flag read back may lag
+	autoFailed := false               // switching auto-fight on failed, don't retry every poll
+	fought := make(map[string]string) // every enemy seen, by battle ID
+	stateMgr.BattleStarted()
+
+	switchToAuto := func(reason string) {
+		auto = e.autoFight(reason)
+		autoFailed = !auto
+	}
+
+	for {
+		battle, err := e.gameClient.GetBattle()
+		if err != nil {
+			return err
+		}
+		if !battle.Active {
+			e.log.WithField("duration", time.Since(start).Round(time.Second)).Debug("Battle over")
+			e.battleOver(stateMgr, fought)
+			return nil
+		}
+		for _, enemy := range battle.Enemies {
+			fought[enemy.ID] = enemy.Name
+		}
+
+		switch {
+		case battle.Auto || auto:
+			// The game fights for us
+		case autoFailed:
+			if battle.MyTurn {
+				if err := e.takeTurn(stateMgr, battle); err != nil {
+					e.log.WithError(err).Debug("Battle turn failed")
+				}
+			}
+		case e.cfg.Combat.AutoFight && len(e.cfg.Combat.Rotation) == 0:
+			switchToAuto("no skill rotation configured")
+		case time.Since(start) > maxBattleTime:
+			switchToAuto("battle taking too long")
+		case battle.MyTurn:
+			if err := e.takeTurn(stateMgr, battle); err != nil {
+				e.log.WithError(err).Warn("Battle turn failed")
+				if e.cfg.Combat.AutoFight {
+					switchToAuto("skills unusable")
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.cfg.Timing.BattlePoll()):
+		}
+	}
+}
+
+// battleOver counts the enemies fought as kills unless the hero lost
+func (e *Engine) battleOver(stateMgr *game.StateManager, fought map[string]string) {
+	hero, err := e.gameClient.GetHeroState()
+	if err != nil || hero.Dead {
+		e.log.Debug("Battle lost, no kills counted")
+		return
+	}
+
+	names := make([]string, 0, len(fought))
+	for _, name := range fought {
+		names = append(names, name)
+	}
+	e.kills = append(e.kills, names...)
+	stateMgr.RecordKills(names)
+	if len(fought) > 0 {
+		e.log.WithField("kills", e.kills).Info("Battle won")
+	}
+}
+
+// takeTurn uses the first ready rotation skill whose conditions hold on the
+// weakest enemy, the default attack when none does
+func (e *Engine) takeTurn(stateMgr *game.StateManager, battle *game.Battle) error {
+	if len(battle.Enemies) == 0 {
+		return nil
+	}
+
+	hero := stateMgr.GetHero()
+	target := weakestEnemy(battle.Enemies)
+
+	skill, err := e.pickSkill(&hero, target, len(battle.Enemies))
+	if err != nil {
+		return err
+	}
+
+	if skill != nil {
+		e.log.WithFields(logrus.Fields{
+			"skill":  skill.Name,
+			"target": target.Name,
+		}).Debug("Using skill")
+		err = e.gameClient.UseSkill(skill.ID, target.ID)
+	} else {
+		err = e.gameClient.BattleAttack(target.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.waitTurnEnd()
+	return nil
+}
+
+// pickSkill returns the skill the rotation calls for, nil for the default attack
+func (e *Engine) pickSkill(hero *game.HeroState, target *game.Enemy, enemies int) (*game.Skill, error) {
+	if len(e.cfg.Combat.Rotation) == 0 {
+		return nil, nil
+	}
+
+	skills, err := e.gameClient.GetSkills()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range e.cfg.Combat.Rotation {
+		skill := findSkill(skills, rule.Skill)
+		if skill == nil || !skill.Ready() || skill.ManaCost > hero.MP {
+			continue
+		}
+		if conditionHolds(rule.When, hero, target, enemies) {
+			return skill, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// waitTurnEnd polls until the game hands the turn over
+func (e *Engine) waitTurnEnd() {
+	deadline := time.Now().Add(turnTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(e.cfg.Timing.BattlePoll())
+		battle, err := e.gameClient.GetBattle()
+		if err != nil || !battle.Active || !battle.MyTurn {
+			return
+		}
+	}
+}
+
+// autoFight switches on the game's auto-fight, reporting whether it did
+func (e *Engine) autoFight(reason string) bool {
+	e.log.WithField("reason", reason).Info("Switching to auto-fight")
+	if err := e.gameClient.ToggleAutoFight(); err != nil {
+		e.log.WithError(err).Warn("Failed to switch on auto-fight")
+		return false
+	}
+	return true
+}
+
+// conditionHolds checks a rotation rule's conditions
+func conditionHolds(c config.BattleCondition, hero *game.HeroState, target *game.Enemy, enemies int) bool {
+	if c.TargetHPBelow > 0 && target.HPPercent >= c.TargetHPBelow {
+		return false
+	}
+	if c.MPAbove > 0 && hero.MPPercent() <= c.MPAbove {
+		return false
+	}
+	if c.MinEnemies > 0 && enemies < c.MinEnemies {
+		return false
+	}
+	return effectsMatch(hero, c.Effect, c.NoEffect)
+}
+
+// findSkill finds a skill by ID or exact name, then by a case-insensitive
+// name substring
+func findSkill(skills []*game.Skill, name string) *game.Skill {
+	if name == "" {
+		return nil
+	}
+	for _, s := range skills {
+		if s.ID == name || strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	lower := strings.ToLower(name)
+	for _, s := range skills {
+		if strings.Contains(strings.ToLower(s.Name), lower) {
+			return s
+		}
+	}
+	return nil
+}
+
+// weakestEnemy returns the enemy with the lowest HP, so kills come quickly
+func weakestEnemy(enemies []*game.Enemy) *game.Enemy {
+	weakest := enemies[0]
+	for _, e := range enemies[1:] {
+		if e.HPPercent < weakest.HPPercent {
+			weakest = e
+		}
+	}
+	return weakest
+}
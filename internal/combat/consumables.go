@@ -0,0 +1,79 @@
+package combat
+
+import (
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+// Consumable rule actions
+const (
+	ConsumableUse     = "use"
+	ConsumableRetreat = "retreat"
+)
+
+// UseConsumables runs the consumable rules in order: "use" rules press
+// their item hotkey, the first "retreat" rule that fires stops the check
+// and reports that the caller should retreat and rest. Each rule waits its
+// cooldown before firing again, giving effects time to show up.
+func (e *Engine) UseConsumables(stateMgr *game.StateManager) (retreat bool) {
+	hero := stateMgr.GetHero()
+	if hero.Dead {
+		return false
+	}
+
+	for i := range e.cfg.Consumables {
+		rule := &e.cfg.Consumables[i]
+		if !consumableHolds(rule.When, &hero) {
+			continue
+		}
+		if time.Since(e.consumableUsed[i]) < time.Duration(rule.Cooldown)*time.Second {
+			continue
+		}
+		e.consumableUsed[i] = time.Now()
+
+		if rule.Action == ConsumableRetreat {
+			e.log.WithFields(logrus.Fields{
+				"rule": rule.Name,
+				"hp":   hero.HPPercent(),
+			}).Warn("Consumable rule calls for a retreat")
+			return true
+		}
+
+		e.log.WithField("rule", rule.Name).Info("Using consumable")
+		if err := e.gameClient.UsePotion(rule.Key); err != nil {
+			e.log.WithError(err).WithField("rule", rule.Name).Warn("Failed to use consumable")
+		}
+	}
+
+	return false
+}
+
+// consumableHolds checks a consumable rule's conditions
+func consumableHolds(c config.ConsumableCondition, hero *game.HeroState) bool {
+	if c.HPBelow > 0 && hero.HPPercent() >= c.HPBelow {
+		return false
+	}
+	if c.MPBelow > 0 && (hero.MPMax == 0 || hero.MPPercent() >= c.MPBelow) {
+		return false
+	}
+	return effectsMatch(hero, c.Effect, c.NoEffect)
+}
+
+// effectsMatch checks that the hero has the effect and lacks noEffect,
+// either left empty to skip the check
+func effectsMatch(hero *game.HeroState, effect, noEffect string) bool {
+	if effect != "" {
+		if _, ok := hero.Effect(effect); !ok {
+			return false
+		}
+	}
+	if noEffect != "" {
+		if _, ok := hero.Effect(noEffect); ok {
+			return false
+		}
+	}
+	return true
+}
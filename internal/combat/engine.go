@@ -1,44 +1,64 @@
 package combat
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/kamilkurek/margonem-bot/internal/behavior"
 	"github.com/kamilkurek/margonem-bot/internal/config"
 	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/navigation"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	threatRadius    = 250.0 // aggressive mobs closer than this are avoided when retreating
+	threatClearance = 80.0  // distance kept from threats along the retreat path
+	retreatDistance = 200.0 // how far to flee when no safe zone is configured
+	moveTimeout     = 10 * time.Second
+)
+
 // Engine manages combat operations
 type Engine struct {
 	gameClient    *game.Client
 	cfg           *config.Config
 	log           *logrus.Logger
 	currentTarget *game.Mob
+	kills         []string // names of the mobs killed in the last battle won
+	priority      []string // target priority, the configured one when nil
+	profile       Profile
+	// consumableUsed remembers when each consumable rule, by index, last fired
+	consumableUsed map[int]time.Time
+	// restSkipUntil holds off resting after a rest ran out of time with HP still low
+	restSkipUntil time.Time
 }
 
 // NewEngine creates a new combat engine
 func NewEngine(gameClient *game.Client, cfg *config.Config, log *logrus.Logger) *Engine {
 	return &Engine{
-		gameClient: gameClient,
-		cfg:        cfg,
-		log:        log,
+		gameClient:     gameClient,
+		cfg:            cfg,
+		log:            log,
+		consumableUsed: make(map[int]time.Time),
 	}
 }
 
 // Tick performs one combat cycle
 func (e *Engine) Tick(stateMgr *game.StateManager) error {
 	hero := stateMgr.GetHero()
+	e.followLevel(&hero)
 	
-	// Check if HP is critically low
-	if hero.HPPercent() < e.cfg.Combat.HPThreshold {
-		e.log.Warn("HP critically low, retreating")
+	// Never start a fight while we should be resting
+	if e.NeedsRest(&hero) {
 		e.currentTarget = nil
-		return e.retreat(&hero)
+		return nil
 	}
 	
 	// Get available mobs
 	mobs := stateMgr.GetMobs()
+	profile := e.currentProfile(&hero)
 	
 	// If we have a current target, check if it's still valid
 	if e.currentTarget != nil {
@@ -52,19 +72,21 @@ func (e *Engine) Tick(stateMgr *game.StateManager) error {
 		}
 		
 		if !valid {
+			// Gone from sight, taken by someone else or killed in a
+			// battle; kills are only counted when a battle is won
 			e.log.Debug("Current target no longer valid")
 			e.currentTarget = nil
 			
 			if e.cfg.Combat.RetargetOnDeath {
 				// Immediately find a new target
-				e.currentTarget = SelectTarget(&hero, mobs, &e.cfg.Combat)
+				e.currentTarget = SelectTarget(&hero, mobs, profile, e.targetPriority(), &e.cfg.Combat, &e.cfg.Profile.HuntingGround)
 			}
 		}
 	}
 	
 	// If no target, find one
 	if e.currentTarget == nil {
-		e.currentTarget = SelectTarget(&hero, mobs, &e.cfg.Combat)
+		e.currentTarget = SelectTarget(&hero, mobs, profile, e.targetPriority(), &e.cfg.Combat, &e.cfg.Profile.HuntingGround)
 		
 		if e.currentTarget == nil {
 			// No targets available
@@ -79,11 +101,62 @@ func (e *Engine) Tick(stateMgr *game.StateManager) error {
 	}
 	
 	// Engage the target
-	return e.engage(&hero, e.currentTarget)
+	return e.engage(&hero, e.currentTarget, profile)
+}
+
+// currentProfile returns the hero's combat profile, logging it when the class changes
+func (e *Engine) currentProfile(hero *game.HeroState) Profile {
+	profile := ProfileFor(hero, &e.cfg.Combat)
+	if profile != e.profile {
+		e.log.WithFields(logrus.Fields{
+			"class":     profile.Class,
+			"range":     profile.Range,
+			"preferred": profile.PreferredDistance,
+			"kite":      profile.Kite,
+		}).Info("Combat profile")
+		e.profile = profile
+	}
+	return profile
+}
+
+// SetTargetPriority replaces the configured target priority, e.g. with
+// quest mobs in front of it
+func (e *Engine) SetTargetPriority(names []string) {
+	e.priority = names
+}
+
+// targetPriority returns the mob names to prefer, best first
+func (e *Engine) targetPriority() []string {
+	if e.priority != nil {
+		return e.priority
+	}
+	return e.cfg.Combat.TargetPriority
+}
+
+// followLevel moves the mob level range along with the hero's level
+func (e *Engine) followLevel(hero *game.HeroState) {
+	lr := e.cfg.Combat.LevelRange
+	if !lr.Auto || hero.Level == 0 {
+		return
+	}
+	
+	minLevel := max(1, hero.Level-lr.Below)
+	maxLevel := hero.Level + lr.Above
+	if minLevel == e.cfg.Combat.MinLevel && maxLevel == e.cfg.Combat.MaxLevel {
+		return
+	}
+	
+	e.cfg.Combat.MinLevel = minLevel
+	e.cfg.Combat.MaxLevel = maxLevel
+	e.log.WithFields(logrus.Fields{
+		"hero": hero.Level,
+		"min":  minLevel,
+		"max":  maxLevel,
+	}).Info("Mob level range follows hero level")
 }
 
-// engage attacks the target
-func (e *Engine) engage(hero *game.HeroState, target *game.Mob) error {
+// engage attacks the target from the class's preferred distance
+func (e *Engine) engage(hero *game.HeroState, target *game.Mob, profile Profile) error {
 	// Calculate distance to target
 	dist := distance(hero.X, hero.Y, target.X, target.Y)
 	
@@ -92,15 +165,27 @@ func (e *Engine) engage(hero *game.HeroState, target *game.Mob) error {
 		"distance": dist,
 	}).Debug("Engaging target")
 	
-	// If too far, move closer first
-	if dist > 50 { // Assuming attack range is ~50 units
-		e.log.Debug("Moving closer to target")
+	// Out of range: walk up to the preferred distance. Too close for a
+	// ranged class: back off to it first.
+	if dist > profile.Range || profile.TooClose(dist) {
+		if dist > profile.Range {
+			e.log.Debug("Moving closer to target")
+		} else {
+			e.log.Debug("Backing off from target")
+		}
 		
 		// Add jitter to movement
-		targetPos := behavior.Point{X: target.X, Y: target.Y}
+		targetPos := profile.attackPosition(hero, target)
 		jitteredPos := behavior.AddJitter(targetPos, e.cfg.Behavior.PathJitter)
 		
-		if err := e.gameClient.MoveTo(jitteredPos.X, jitteredPos.Y); err != nil {
+		err := e.gameClient.MoveToAndWait(jitteredPos.X, jitteredPos.Y, game.ArriveTolerance, moveTimeout)
+		if errors.Is(err, game.ErrBlocked) || errors.Is(err, game.ErrTimeout) {
+			// Unreachable for now, let the next tick pick another mob
+			e.log.WithError(err).WithField("target", target.Name).Info("Target unreachable, dropping it")
+			e.currentTarget = nil
+			return nil
+		}
+		if err != nil {
 			return fmt.Errorf("failed to move to target: %w", err)
 		}
 		
@@ -128,26 +213,178 @@ func (e *Engine) engage(hero *game.HeroState, target *game.Mob) error {
 }
 
 
-// retreat moves the hero away from danger
-func (e *Engine) retreat(hero *game.HeroState) error {
-	e.log.Warn("Retreating from combat")
+// NeedsRest reports whether HP has dropped low enough to stop hunting
+func (e *Engine) NeedsRest(hero *game.HeroState) bool {
+	if time.Now().Before(e.restSkipUntil) {
+		return false
+	}
+	return !hero.Dead && hero.HPPercent() < e.cfg.Combat.HPThreshold
+}
+
+// Rest retreats to safety and waits until HP and MP climb back above the
+// resume thresholds (or the max rest time passes), then returns to the hunting ground
+func (e *Engine) Rest(ctx context.Context, stateMgr *game.StateManager) error {
+	e.log.Warn("HP critically low, resting")
+	e.currentTarget = nil
+	
+	start := time.Now()
+	defer func() {
+		stateMgr.RecordRest(time.Since(start))
+	}()
 	
-	// Move to a random nearby position away from current location
-	offset := behavior.RandomOffset(100)
-	newX := hero.X + offset.X
-	newY := hero.Y + offset.Y
+	if err := e.retreat(stateMgr); err != nil {
+		return err
+	}
+	
+	if e.cfg.Combat.RestSitKey != "" {
+		if err := e.gameClient.Sit(e.cfg.Combat.RestSitKey); err != nil {
+			e.log.WithError(err).Debug("Failed to sit down")
+		}
+	}
+	if e.cfg.Combat.RestItemKey != "" {
+		if err := e.gameClient.UsePotion(e.cfg.Combat.RestItemKey); err != nil {
+			e.log.WithError(err).Debug("Failed to use regeneration item")
+		}
+	}
+	
+	if !e.waitForRegen(ctx, stateMgr) {
+		return nil
+	}
+	
+	e.log.WithField("rested", time.Since(start).Round(time.Second)).Info("Rest finished")
+	return e.returnToHuntingGround(stateMgr)
+}
+
+// retreat walks the hero to the nearest safe zone, steering around aggressive mobs
+func (e *Engine) retreat(stateMgr *game.StateManager) error {
+	hero := stateMgr.GetHero()
+	from := behavior.Point{X: hero.X, Y: hero.Y}
+	threats := nearbyThreats(&hero, stateMgr.GetMobs())
 	
-	if err := e.gameClient.MoveTo(newX, newY); err != nil {
+	dest := navigation.FleePoint(from, threats, retreatDistance)
+	if zone, p, ok := navigation.NearestSafeZone(e.cfg.Profile.SafeZones, hero.MapID, from); ok {
+		e.log.WithField("zone", zone.Name).Info("Retreating to safe zone")
+		dest = p
+	} else {
+		e.log.Debug("No safe zone on this map, moving away from mobs")
+	}
+	
+	path := navigation.EscapePath(from, dest, threats, 50, threatClearance)
+	if err := e.walk(path); err != nil {
 		return fmt.Errorf("failed to retreat: %w", err)
 	}
 	
-	// Wait a bit
-	behavior.LongPause()
+	return nil
+}
+
+// waitForRegen blocks until HP and MP reach the resume thresholds or the
+// max rest time passes. It returns false if the rest was interrupted by
+// combat, death or shutdown.
+func (e *Engine) waitForRegen(ctx context.Context, stateMgr *game.StateManager) bool {
+	timeout := time.Duration(e.cfg.Combat.MaxRestTime) * time.Second
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		hero := stateMgr.GetHero()
+		if hero.Dead || hero.InCombat {
+			e.log.Warn("Rest interrupted")
+			return false
+		}
+		if e.regenerated(&hero) {
+			return true
+		}
+		
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline:
+			e.log.WithFields(logrus.Fields{
+				"hp": hero.HPPercent(),
+				"mp": hero.MPPercent(),
+			}).Warn("Max rest time reached, resuming anyway")
+			if e.NeedsRest(&hero) {
+				// Resting longer won't help, hunt on for a while
+				// instead of going straight back to rest
+				e.restSkipUntil = time.Now().Add(timeout)
+			}
+			return true
+		case <-ticker.C:
+		}
+	}
+}
+
+// regenerated reports whether HP and MP are back at the resume thresholds.
+// Heroes without mana only wait for HP.
+func (e *Engine) regenerated(hero *game.HeroState) bool {
+	if hero.HPPercent() < e.cfg.Combat.ResumeHPThreshold {
+		return false
+	}
+	return hero.MPMax == 0 || hero.MPPercent() >= e.cfg.Combat.ResumeMPThreshold
+}
+
+// returnToHuntingGround walks back to the hunting ground center when on the same map
+func (e *Engine) returnToHuntingGround(stateMgr *game.StateManager) error {
+	hero := stateMgr.GetHero()
+	ground := e.cfg.Profile.HuntingGround
+	if hero.Dead || hero.InCombat || hero.MapID != ground.MapID {
+		return nil
+	}
+	
+	from := behavior.Point{X: hero.X, Y: hero.Y}
+	if navigation.GroundContains(ground, from) {
+		return nil
+	}
+	to := behavior.AddJitter(navigation.GroundCenter(ground), e.cfg.Behavior.PathJitter)
+	
+	e.log.Info("Returning to hunting ground")
+	if err := e.walk(behavior.GeneratePath(from, to, 50)); err != nil {
+		return fmt.Errorf("failed to return to hunting ground: %w", err)
+	}
 	
 	return nil
 }
 
+// walk moves along a path with short human-like pauses between steps
+func (e *Engine) walk(path []behavior.Point) error {
+	for _, p := range path {
+		if err := e.gameClient.MoveToAndWait(p.X, p.Y, game.ArriveTolerance, moveTimeout); err != nil {
+			return err
+		}
+		
+		behavior.SleepRange(
+			e.cfg.Behavior.GetMinDelay()/2,
+			e.cfg.Behavior.GetMaxDelay()/2,
+		)
+	}
+	return nil
+}
+
+// nearbyThreats returns positions of aggressive mobs close to the hero
+func nearbyThreats(hero *game.HeroState, mobs []*game.Mob) []behavior.Point {
+	threats := make([]behavior.Point, 0)
+	for _, m := range mobs {
+		if !m.Alive || !m.Aggressive {
+			continue
+		}
+		if distance(hero.X, hero.Y, m.X, m.Y) > threatRadius {
+			continue
+		}
+		threats = append(threats, behavior.Point{X: m.X, Y: m.Y})
+	}
+	return threats
+}
+
+// TakeKills returns the names of the mobs killed since the last call
+func (e *Engine) TakeKills() []string {
+	kills := e.kills
+	e.kills = nil
+	return kills
+}
+
 // Reset resets the combat state
 func (e *Engine) Reset() {
 	e.currentTarget = nil
+	e.kills = nil
 }
@@ -0,0 +1,92 @@
+package combat
+
+import (
+	"math"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+)
+
+// Profile describes how the hero's class fights
+type Profile struct {
+	Class             game.Profession
+	Range             float64 // distance from which the hero can attack
+	PreferredDistance float64 // distance to attack from after walking up
+	Kite              bool    // back off from mobs closing in
+}
+
+// defaultEngageRange is the attack distance of unknown classes when
+// engageRange isn't configured
+const defaultEngageRange = 50.0
+
+// classProfiles are the built-in profiles: melee classes walk up to the
+// mob, ranged classes attack from a distance and keep it
+var classProfiles = map[game.Profession]Profile{
+	game.Warrior:     {Range: 50, PreferredDistance: 32},
+	game.Paladin:     {Range: 50, PreferredDistance: 32},
+	game.BladeDancer: {Range: 50, PreferredDistance: 32},
+	game.Mage:        {Range: 200, PreferredDistance: 150, Kite: true},
+	game.Hunter:      {Range: 250, PreferredDistance: 180, Kite: true},
+	game.Tracker:     {Range: 200, PreferredDistance: 150, Kite: true},
+}
+
+// ProfileFor returns the combat profile of the hero's class. The configured
+// profession wins over the one read from the game, configured class
+// settings over EngageRange and EngageRange over the built-in ones.
+func ProfileFor(hero *game.HeroState, cfg *config.CombatConfig) Profile {
+	class := hero.Profession
+	if p := game.ParseProfession(cfg.Profession); p != "" {
+		class = p
+	}
+
+	profile, ok := classProfiles[class]
+	if !ok {
+		profile = Profile{Range: defaultEngageRange, PreferredDistance: defaultEngageRange}
+	}
+	profile.Class = class
+
+	if cfg.EngageRange > 0 {
+		profile.Range = cfg.EngageRange
+		if !ok {
+			profile.PreferredDistance = cfg.EngageRange
+		}
+	}
+
+	if override, ok := cfg.Classes[string(class)]; ok && class != "" {
+		if override.Range > 0 {
+			profile.Range = override.Range
+		}
+		if override.PreferredDistance > 0 {
+			profile.PreferredDistance = override.PreferredDistance
+		}
+		if override.Kite != nil {
+			profile.Kite = *override.Kite
+		}
+	}
+
+	// Never try to attack from further than the class reaches
+	profile.PreferredDistance = math.Min(profile.PreferredDistance, profile.Range)
+	return profile
+}
+
+// TooClose reports whether a kiting class should back off before attacking
+func (p Profile) TooClose(dist float64) bool {
+	return p.Kite && dist < p.PreferredDistance/2
+}
+
+// attackPosition is the point at the preferred distance from the target,
+// on the hero's side of it
+func (p Profile) attackPosition(hero *game.HeroState, target *game.Mob) behavior.Point {
+	dx, dy := hero.X-target.X, hero.Y-target.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		// Standing on the mob: back off in any direction
+		dx, dy, length = 1, 0, 1
+	}
+
+	return behavior.Point{
+		X: target.X + dx/length*p.PreferredDistance,
+		Y: target.Y + dy/length*p.PreferredDistance,
+	}
+}
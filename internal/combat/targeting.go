@@ -3,8 +3,10 @@ package combat
 import (
 	"math"
 
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
 	"github.com/kamilkurek/margonem-bot/internal/config"
 	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/navigation"
 )
 
 // TargetScore represents a mob with its score
@@ -14,8 +16,11 @@ type TargetScore struct {
 	Distance float64
 }
 
-// SelectTarget finds the best mob to attack based on configuration
-func SelectTarget(hero *game.HeroState, mobs []*game.Mob, cfg *config.CombatConfig) *game.Mob {
+// SelectTarget finds the best mob to attack based on configuration. When
+// the hero is on the hunting ground's map, only mobs inside it are engaged.
+// Mobs already within the class's range need no walking and score higher,
+// and so do mobs early in priority.
+func SelectTarget(hero *game.HeroState, mobs []*game.Mob, profile Profile, priority []string, cfg *config.CombatConfig, ground *config.HuntingGround) *game.Mob {
 	if len(mobs) == 0 {
 		return nil
 	}
@@ -28,6 +33,17 @@ func SelectTarget(hero *game.HeroState, mobs []*game.Mob, cfg *config.CombatConf
 			continue
 		}
 		
+		// Stay inside the hunting ground
+		if ground != nil && hero.MapID == ground.MapID &&
+			!navigation.GroundContains(*ground, behavior.Point{X: mob.X, Y: mob.Y}) {
+			continue
+		}
+		
+		// Leave mobs other players are fighting alone
+		if mob.Contested {
+			continue
+		}
+		
 		// Filter by level range
 		if cfg.MinLevel > 0 && mob.Level < cfg.MinLevel {
 			continue
@@ -39,13 +55,13 @@ func SelectTarget(hero *game.HeroState, mobs []*game.Mob, cfg *config.CombatConf
 		// Calculate distance
 		dist := distance(hero.X, hero.Y, mob.X, mob.Y)
 		
-		// Filter by max engage distance
-		if cfg.MaxEngageDistance > 0 && dist > cfg.MaxEngageDistance {
+		// Filter by max engage distance, never below what the class reaches
+		if cfg.MaxEngageDistance > 0 && dist > math.Max(cfg.MaxEngageDistance, profile.Range) {
 			continue
 		}
 		
-		// Calculate score
-		score := scoreMob(mob, dist, cfg.TargetPriority)
+		// Calculate score from the distance left to walk
+		score := scoreMob(mob, math.Max(0, dist-profile.Range), priority)
 		
 		candidates = append(candidates, TargetScore{
 			Mob:      mob,
@@ -0,0 +1,45 @@
+package combat
+
+import (
+	"context"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/events"
+)
+
+// Triggers returns a channel that fires every interval and as soon as mobs,
+// the battle or the hero change, so the hunt loop reacts to a new mob or a
+// hit taken without waiting for the next tick. Triggers arriving while the
+// loop is busy are coalesced into one.
+func Triggers(ctx context.Context, interval time.Duration, bus *events.Bus) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	mobs := bus.Subscribe(events.MobsChanged, 1)
+	battle := bus.Subscribe(events.BattleChanged, 1)
+	hero := bus.Subscribe(events.HeroChanged, 1)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-mobs:
+				ticker.Reset(interval)
+			case <-battle:
+				ticker.Reset(interval)
+			case <-hero:
+				ticker.Reset(interval)
+			}
+
+			select {
+			case out <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return out
+}
@@ -1,14 +1,22 @@
 package config
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Config represents the complete bot configuration
 type Config struct {
-	Account  AccountConfig  `yaml:"account"`
-	Profile  ProfileConfig  `yaml:"profile,omitempty"`
-	Combat   CombatConfig   `yaml:"combat"`
-	Behavior BehaviorConfig `yaml:"behavior"`
-	Runtime  RuntimeConfig  `yaml:"runtime"`
+	Account     AccountConfig    `yaml:"account"`
+	Profile     ProfileConfig    `yaml:"profile,omitempty"`
+	Combat      CombatConfig     `yaml:"combat"`
+	Loot        LootConfig       `yaml:"loot"`
+	Chat        ChatConfig       `yaml:"chat"`
+	Players     PlayersConfig    `yaml:"players"`
+	Behavior    BehaviorConfig   `yaml:"behavior"`
+	Timing      TimingConfig     `yaml:"timing"`
+	Consumables []ConsumableRule `yaml:"consumables,omitempty"` // checked every hunt tick, in order
+	Runtime     RuntimeConfig    `yaml:"runtime"`
 }
 
 // AccountConfig holds account credentials and connection info
@@ -25,24 +33,65 @@ type ProfileConfig struct {
 	HuntingGround HuntingGround  `yaml:"huntingGround"`
 	Waypoints     []Waypoint     `yaml:"waypoints"`
 	TownRespawn   RespawnPoint   `yaml:"townRespawn"`
+	SafeZones     []SafeZone     `yaml:"safeZones,omitempty"`
+	Town          TownConfig     `yaml:"town,omitempty"`
+	Quests        []QuestConfig  `yaml:"quests,omitempty"`
+	// AlternateGrounds are rotated with the hunting ground, see HuntingGround.Leave
+	AlternateGrounds []HuntingGround `yaml:"alternateGrounds,omitempty"`
 }
 
 // HuntingGround defines the area to hunt in
 type HuntingGround struct {
+	Name    string  `yaml:"name,omitempty"`
 	MapID  string  `yaml:"mapId"`
 	CenterX float64 `yaml:"centerX"`
 	CenterY float64 `yaml:"centerY"`
 	Radius  float64 `yaml:"radius"`
+	Weight  float64 `yaml:"weight,omitempty"` // relative chance of being picked when rotating (default 1)
+	// Areas replace the CenterX/CenterY/Radius circle with a union of shapes
+	Areas []Shape `yaml:"areas,omitempty"`
+	// Exclude cuts shapes out of the hunting ground (cliffs, aggressive packs)
+	Exclude []Shape `yaml:"exclude,omitempty"`
+	// Waypoints lead here from the other hunting grounds; skipped when already on MapID
+	Waypoints []Waypoint   `yaml:"waypoints,omitempty"`
+	Leave     LeaveRules   `yaml:"leave,omitempty"`
+	Patrol    PatrolConfig `yaml:"patrol,omitempty"`
+	// Hero levels the ground suits; the hero moves on to another ground once past MaxHeroLevel
+	MinHeroLevel int `yaml:"minHeroLevel,omitempty"`
+	MaxHeroLevel int `yaml:"maxHeroLevel,omitempty"`
+}
+
+// PatrolConfig defines how the bot walks around a hunting ground when no mobs are in sight
+type PatrolConfig struct {
+	Mode        string        `yaml:"mode"`        // "random" (default), "loop", "pingpong" or "spawns"
+	Points      []PatrolPoint `yaml:"points"`      // named points for loop, pingpong and spawns
+	SpawnMemory int           `yaml:"spawnMemory"` // seconds a mob sighting at a spawn point counts as recent
+}
+
+// PatrolPoint is a named spot on the hunting ground
+type PatrolPoint struct {
+	Name string  `yaml:"name"`
+	X    float64 `yaml:"x"`
+	Y    float64 `yaml:"y"`
+}
+
+// LeaveRules decide when to rotate away from a hunting ground. Zero values disable a rule.
+type LeaveRules struct {
+	MinMobs      int  `yaml:"minMobs"`      // leave when fewer live mobs stay on the ground for a minute
+	PlayerNearby bool `yaml:"playerNearby"` // leave when another player is on the ground
+	MaxMinutes   int  `yaml:"maxMinutes"`   // leave after this many minutes
 }
 
 // Waypoint represents a navigation point
 type Waypoint struct {
-	MapID       string  `yaml:"mapId"`
-	X           float64 `yaml:"x"`
-	Y           float64 `yaml:"y"`
-	Description string  `yaml:"description"`
-	Action      string  `yaml:"action"` // "walk", "portal", "door"
-	Selector    string  `yaml:"selector,omitempty"`
+	MapID       string   `yaml:"mapId"`
+	X           float64  `yaml:"x"`
+	Y           float64  `yaml:"y"`
+	Description string   `yaml:"description"`
+	Action      string   `yaml:"action"` // "walk", "portal", "door", "dialog"
+	Selector    string   `yaml:"selector,omitempty"`
+	NPCID       string   `yaml:"npcId,omitempty"`  // NPC to talk to for "dialog"
+	Dialog      []string `yaml:"dialog,omitempty"` // replies in order: option number or text to match
 }
 
 // RespawnPoint defines where the character respawns
@@ -52,15 +101,208 @@ type RespawnPoint struct {
 	Y     float64 `yaml:"y"`
 }
 
+// Point is a map coordinate used in area definitions
+type Point struct {
+	X float64 `yaml:"x"`
+	Y float64 `yaml:"y"`
+}
+
+// Shape is either a circle around X/Y (a single point when Radius is 0)
+// or, when Polygon is set, the area enclosed by its vertices
+type Shape struct {
+	X       float64 `yaml:"x"`
+	Y       float64 `yaml:"y"`
+	Radius  float64 `yaml:"radius,omitempty"`
+	Polygon []Point `yaml:"polygon,omitempty"`
+}
+
+// SafeZone defines a spot on a map to retreat to when HP is low. Only
+// zones on the map the hero is hunting on are used, so MapID must be the
+// map of one of the hunting grounds.
+type SafeZone struct {
+	Name  string `yaml:"name"`
+	MapID string `yaml:"mapId"`
+	Shape `yaml:",inline"`
+}
+
+// TownConfig defines trips to a merchant to sell junk and restock consumables
+type TownConfig struct {
+	Enabled      bool           `yaml:"enabled"`
+	MinFreeSlots int            `yaml:"minFreeSlots"` // go to town when fewer bag slots are free
+	Waypoints    []Waypoint     `yaml:"waypoints"`    // route from the hunting ground to the merchant
+	Merchant     MerchantConfig `yaml:"merchant"`
+	Junk         ItemFilter     `yaml:"junk"`        // items to sell
+	Restock      []RestockItem  `yaml:"restock"`     // consumables to buy
+	TeleportKey  string         `yaml:"teleportKey"` // hotkey of a town teleport scroll, last resort when stuck
+}
+
+// MerchantConfig identifies the NPC to trade with
+type MerchantConfig struct {
+	NPCID string  `yaml:"npcId"`
+	MapID string  `yaml:"mapId"`
+	X     float64 `yaml:"x"`
+	Y     float64 `yaml:"y"`
+}
+
+// RestockItem is a consumable kept in the bags
+type RestockItem struct {
+	Name        string `yaml:"name"`
+	Quantity    int    `yaml:"quantity"`    // buy up to this amount
+	MinQuantity int    `yaml:"minQuantity"` // go to town when fewer are carried
+}
+
+// QuestConfig ties the hunting profile to a kill quest. Quests are
+// worked through in order; after the last one the bot hunts on as usual.
+type QuestConfig struct {
+	Name    string        `yaml:"name"`             // quest name as shown in the quest log (substring)
+	Targets []QuestTarget `yaml:"targets"`          // mobs to kill
+	Accept  *NPCDialog    `yaml:"accept,omitempty"` // how to take the quest, nil if already active
+	TurnIn  NPCDialog     `yaml:"turnIn"`           // how to hand the quest in
+}
+
+// QuestTarget is a kill objective
+type QuestTarget struct {
+	Mob   string `yaml:"mob"`
+	Count int    `yaml:"count"`
+}
+
+// NPCDialog describes how to reach an NPC and what to answer
+type NPCDialog struct {
+	NPCID     string     `yaml:"npcId"`
+	MapID     string     `yaml:"mapId"`
+	X         float64    `yaml:"x"`
+	Y         float64    `yaml:"y"`
+	Waypoints []Waypoint `yaml:"waypoints"` // route from the hunting ground to the NPC
+	Dialog    []string   `yaml:"dialog"`    // replies in order: option number or text to match
+}
+
 // CombatConfig defines combat behavior
 type CombatConfig struct {
 	HPThreshold       int      `yaml:"hpThreshold"`       // % HP to retreat
+	ResumeHPThreshold int      `yaml:"resumeHpThreshold"` // % HP to resume hunting after resting
+	ResumeMPThreshold int      `yaml:"resumeMpThreshold"` // % MP to resume hunting after resting
+	MaxRestTime       int      `yaml:"maxRestTime"`       // max seconds to rest before resuming anyway
+	RestSitKey        string   `yaml:"restSitKey"`        // hotkey to sit down while resting (optional)
+	RestItemKey       string   `yaml:"restItemKey"`       // hotkey of a regeneration item (optional)
 	MPThreshold       int      `yaml:"mpThreshold"`       // % MP to consider
 	TargetPriority    []string `yaml:"targetPriority"`    // mob names by priority
 	RetargetOnDeath   bool     `yaml:"retargetOnDeath"`   // find new target immediately
 	MaxEngageDistance float64  `yaml:"maxEngageDistance"` // max distance to chase
+	EngageRange       float64  `yaml:"engageRange"`       // distance from which the hero can attack, 0 = the class default
+	Profession        string   `yaml:"profession"`        // class override, empty = read from the game
 	MinLevel          int      `yaml:"minLevel"`          // min mob level
 	MaxLevel          int      `yaml:"maxLevel"`          // max mob level
+	// Classes override the built-in combat profile per class
+	// (warrior, mage, hunter, paladin, blade_dancer, tracker)
+	Classes map[string]ClassProfile `yaml:"classes,omitempty"`
+	// LevelRange replaces MinLevel and MaxLevel with a range around the hero's level
+	LevelRange LevelRange `yaml:"levelRange,omitempty"`
+	// Rotation picks the skill for each battle turn: the first rule whose
+	// skill is ready and whose conditions hold is used, the default attack otherwise
+	Rotation  []SkillRule `yaml:"rotation,omitempty"`
+	AutoFight bool        `yaml:"autoFight"` // fall back to the game's auto-fight when skills can't be used
+}
+
+// LevelRange makes MinLevel and MaxLevel follow the hero's level
+type LevelRange struct {
+	Auto  bool `yaml:"auto"`
+	Below int  `yaml:"below"` // lowest mob level: hero level minus this
+	Above int  `yaml:"above"` // highest mob level: hero level plus this
+}
+
+// SkillRule uses a skill in battle when all its set conditions hold
+type SkillRule struct {
+	Skill string          `yaml:"skill"` // skill name (case-insensitive substring) or ID
+	When  BattleCondition `yaml:"when,omitempty"`
+}
+
+// BattleCondition restricts when a skill is used. Zero values are ignored.
+type BattleCondition struct {
+	TargetHPBelow int    `yaml:"targetHpBelow"` // target HP % below this
+	MPAbove       int    `yaml:"mpAbove"`       // own MP % above this
+	MinEnemies    int    `yaml:"minEnemies"`    // at least this many enemies alive
+	Effect        string `yaml:"effect"`        // hero has this buff or debuff (name substring)
+	NoEffect      string `yaml:"noEffect"`      // hero lacks this buff or debuff
+}
+
+// ConsumableRule uses an item or retreats when all its set conditions hold
+type ConsumableRule struct {
+	Name     string              `yaml:"name"`
+	Action   string              `yaml:"action"` // "use" (default) or "retreat"
+	Key      string              `yaml:"key"`    // hotkey of the item to use
+	When     ConsumableCondition `yaml:"when"`
+	Cooldown int                 `yaml:"cooldown"` // seconds before the rule can fire again
+}
+
+// ConsumableCondition restricts when a consumable rule fires. Zero values are ignored.
+type ConsumableCondition struct {
+	HPBelow  int    `yaml:"hpBelow"`  // own HP % below this
+	MPBelow  int    `yaml:"mpBelow"`  // own MP % below this
+	Effect   string `yaml:"effect"`   // hero has this buff or debuff (name substring)
+	NoEffect string `yaml:"noEffect"` // hero lacks it, e.g. an elixir that ran out
+}
+
+// ClassProfile describes how a class fights. Zero values keep the built-in defaults.
+type ClassProfile struct {
+	Range             float64 `yaml:"range"`             // distance from which the class can attack
+	PreferredDistance float64 `yaml:"preferredDistance"` // distance to attack from after walking up
+	Kite              *bool   `yaml:"kite,omitempty"`    // back off from mobs closing in
+}
+
+// ItemFilter selects items by name, rarity, type and value.
+// Names match case-insensitively as substrings.
+type ItemFilter struct {
+	Whitelist []string `yaml:"whitelist"` // always matched
+	Blacklist []string `yaml:"blacklist"` // never matched
+	Rarities  []string `yaml:"rarities"`  // allowed rarities (empty = any)
+	Types     []string `yaml:"types"`     // allowed item types (empty = any)
+	MinValue  int      `yaml:"minValue"`  // min merchant value in gold
+	MaxValue  int      `yaml:"maxValue"`  // max merchant value in gold (0 = no limit)
+}
+
+// LootConfig defines which drops are picked up after a kill
+type LootConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	ItemFilter `yaml:",inline"`
+}
+
+// ChatConfig defines chat watching and reactions
+type ChatConfig struct {
+	Enabled      bool       `yaml:"enabled"`
+	PollInterval int        `yaml:"pollInterval"` // seconds between chat reads
+	Rules        []ChatRule `yaml:"rules"`
+}
+
+// ChatRule reacts to chat messages. Author, Text and Channel are regular
+// expressions; empty ones match anything. All set patterns must match.
+type ChatRule struct {
+	Name         string   `yaml:"name"`
+	Author       string   `yaml:"author"`
+	Text         string   `yaml:"text"`
+	Channel      string   `yaml:"channel"`
+	Actions      []string `yaml:"actions"`      // "pause", "screenshot", "log", "notify"
+	PauseSeconds int      `yaml:"pauseSeconds"` // how long "pause" stops the bot
+}
+
+// PlayersConfig defines how to behave around other players
+type PlayersConfig struct {
+	NearbyRadius   float64  `yaml:"nearbyRadius"`   // distance at which a player counts as nearby
+	PauseAfter     int      `yaml:"pauseAfter"`     // pause once a player stays nearby this many seconds (0 = never)
+	PauseDuration  int      `yaml:"pauseDuration"`  // seconds to pause
+	AvoidContested bool     `yaml:"avoidContested"` // skip mobs another player is fighting
+	ContestRadius  float64  `yaml:"contestRadius"`  // a fighting player this close to a mob is fighting it
+	CrowdedAt      int      `yaml:"crowdedAt"`      // switch hunting ground at this many players on it (0 = never)
+	Ignore         []string `yaml:"ignore"`         // nicks to ignore (friends, own characters)
+}
+
+// Ignores reports whether a player is on the ignore list
+func (p *PlayersConfig) Ignores(nick string) bool {
+	for _, n := range p.Ignore {
+		if strings.EqualFold(n, nick) {
+			return true
+		}
+	}
+	return false
 }
 
 // BehaviorConfig defines human-like behavior patterns
@@ -74,6 +316,47 @@ type BehaviorConfig struct {
 }
 
 
+// TimingConfig defines how often the game is read and the bot acts
+type TimingConfig struct {
+	PollMs         int     `yaml:"pollMs"`         // state poll interval while hunting
+	BattlePollMs   int     `yaml:"battlePollMs"`   // state poll interval during a battle
+	IdlePollMs     int     `yaml:"idlePollMs"`     // state poll interval while paused or with no mobs in sight
+	CombatTickMs   int     `yaml:"combatTickMs"`   // hunt loop tick when no state change wakes it earlier
+	PatrolInterval int     `yaml:"patrolInterval"` // seconds between patrols when no mobs are in sight
+	StuckWindow    int     `yaml:"stuckWindow"`    // seconds of walking checked for progress
+	StuckThreshold float64 `yaml:"stuckThreshold"` // pixels of progress toward the goal below which the hero is stuck
+}
+
+// Poll returns the state poll interval while hunting
+func (t *TimingConfig) Poll() time.Duration {
+	return time.Duration(t.PollMs) * time.Millisecond
+}
+
+// BattlePoll returns the state poll interval during a battle
+func (t *TimingConfig) BattlePoll() time.Duration {
+	return time.Duration(t.BattlePollMs) * time.Millisecond
+}
+
+// IdlePoll returns the state poll interval while idle
+func (t *TimingConfig) IdlePoll() time.Duration {
+	return time.Duration(t.IdlePollMs) * time.Millisecond
+}
+
+// CombatTick returns the hunt loop tick
+func (t *TimingConfig) CombatTick() time.Duration {
+	return time.Duration(t.CombatTickMs) * time.Millisecond
+}
+
+// Patrol returns the interval between patrols
+func (t *TimingConfig) Patrol() time.Duration {
+	return time.Duration(t.PatrolInterval) * time.Second
+}
+
+// Stuck returns the stuck detection window
+func (t *TimingConfig) Stuck() time.Duration {
+	return time.Duration(t.StuckWindow) * time.Second
+}
+
 // RuntimeConfig defines runtime behavior
 type RuntimeConfig struct {
 	Headless       bool   `yaml:"headless"`
@@ -83,6 +366,7 @@ type RuntimeConfig struct {
 	ViewportHeight int    `yaml:"viewportHeight"`
 	ScreenshotDir  string `yaml:"screenshotDir"`
 	AutoDetectMode bool   `yaml:"autoDetectMode"` // Auto-detect location and mobs
+	HeatmapFile    string `yaml:"heatmapFile"`    // Where learned mob spawns are kept
 }
 
 // GetMinDelay returns minimum delay as duration
@@ -107,6 +391,9 @@ func (c *Config) SetDefaults() {
 	if c.Runtime.ScreenshotDir == "" {
 		c.Runtime.ScreenshotDir = "./screenshots"
 	}
+	if c.Runtime.HeatmapFile == "" {
+		c.Runtime.HeatmapFile = "./data/heatmap.json"
+	}
 	if c.Behavior.MinDelayMs == 0 {
 		c.Behavior.MinDelayMs = 1000
 	}
@@ -122,7 +409,63 @@ func (c *Config) SetDefaults() {
 	if c.Combat.MaxEngageDistance == 0 {
 		c.Combat.MaxEngageDistance = 200.0
 	}
+	if c.Combat.LevelRange.Auto && c.Combat.LevelRange.Below == 0 && c.Combat.LevelRange.Above == 0 {
+		c.Combat.LevelRange.Below = 5
+		c.Combat.LevelRange.Above = 3
+	}
 	if c.Combat.HPThreshold == 0 {
 		c.Combat.HPThreshold = 30
 	}
+	if c.Combat.ResumeHPThreshold == 0 {
+		c.Combat.ResumeHPThreshold = 80
+	}
+	if c.Chat.PollInterval == 0 {
+		c.Chat.PollInterval = 3
+	}
+	for i := range c.Chat.Rules {
+		if c.Chat.Rules[i].PauseSeconds == 0 {
+			c.Chat.Rules[i].PauseSeconds = 300
+		}
+	}
+	if c.Players.NearbyRadius == 0 {
+		c.Players.NearbyRadius = 150
+	}
+	if c.Players.PauseDuration == 0 {
+		c.Players.PauseDuration = 120
+	}
+	if c.Players.ContestRadius == 0 {
+		c.Players.ContestRadius = 60
+	}
+	if c.Combat.MaxRestTime == 0 {
+		c.Combat.MaxRestTime = 120
+	}
+	for i := range c.Consumables {
+		if c.Consumables[i].Action == "" {
+			c.Consumables[i].Action = "use"
+		}
+		if c.Consumables[i].Cooldown == 0 {
+			c.Consumables[i].Cooldown = 10
+		}
+	}
+	if c.Timing.PollMs == 0 {
+		c.Timing.PollMs = 1000
+	}
+	if c.Timing.BattlePollMs == 0 {
+		c.Timing.BattlePollMs = 300
+	}
+	if c.Timing.IdlePollMs == 0 {
+		c.Timing.IdlePollMs = 2500
+	}
+	if c.Timing.CombatTickMs == 0 {
+		c.Timing.CombatTickMs = 2000
+	}
+	if c.Timing.PatrolInterval == 0 {
+		c.Timing.PatrolInterval = 30
+	}
+	if c.Timing.StuckWindow == 0 {
+		c.Timing.StuckWindow = 30
+	}
+	if c.Timing.StuckThreshold == 0 {
+		c.Timing.StuckThreshold = 10
+	}
 }
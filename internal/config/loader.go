@@ -3,10 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// classNames are the hero classes combat settings may name, as the game
+// package spells its professions. The game's one-letter class codes are
+// their first letters.
+var classNames = []string{"warrior", "mage", "hunter", "paladin", "blade_dancer", "tracker"}
+
+// knownClass reports whether s is a class name or a game class code
+func knownClass(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, name := range classNames {
+		if s == name || s == name[:1] {
+			return true
+		}
+	}
+	return false
+}
+
 // Load reads and parses the configuration file
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -28,6 +48,27 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// LoadRuntime reads only the runtime settings, with defaults applied. It
+// skips validation, so offline tools work without credentials or a profile.
+func LoadRuntime(path string) (*RuntimeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	cfg.SetDefaults()
+	return &cfg.Runtime, nil
+}
+
+// maxStuckWindow is the longest stuck window in seconds; the state manager
+// keeps two minutes of hero positions
+const maxStuckWindow = 120
+
 // validate checks the configuration for errors
 func validate(cfg *Config) error {
 	if cfg.Account.Username == "" {
@@ -48,8 +89,8 @@ func validate(cfg *Config) error {
 		if cfg.Profile.HuntingGround.MapID == "" {
 			return fmt.Errorf("profile.huntingGround.mapId is required (or enable autoDetectMode)")
 		}
-		if cfg.Profile.HuntingGround.Radius <= 0 {
-			return fmt.Errorf("profile.huntingGround.radius must be positive (or enable autoDetectMode)")
+		if cfg.Profile.HuntingGround.Radius <= 0 && len(cfg.Profile.HuntingGround.Areas) == 0 {
+			return fmt.Errorf("profile.huntingGround needs a positive radius or areas (or enable autoDetectMode)")
 		}
 	}
 
@@ -59,7 +100,208 @@ func validate(cfg *Config) error {
 	if cfg.Combat.MPThreshold < 0 || cfg.Combat.MPThreshold > 100 {
 		return fmt.Errorf("combat.mpThreshold must be between 0 and 100")
 	}
+	if cfg.Combat.ResumeHPThreshold < cfg.Combat.HPThreshold || cfg.Combat.ResumeHPThreshold > 100 {
+		return fmt.Errorf("combat.resumeHpThreshold must be between hpThreshold and 100")
+	}
+	if cfg.Combat.ResumeMPThreshold < 0 || cfg.Combat.ResumeMPThreshold > 100 {
+		return fmt.Errorf("combat.resumeMpThreshold must be between 0 and 100")
+	}
+	if cfg.Combat.MaxRestTime < 0 {
+		return fmt.Errorf("combat.maxRestTime must be non-negative")
+	}
+	if cfg.Combat.EngageRange < 0 {
+		return fmt.Errorf("combat.engageRange must be non-negative")
+	}
+	if cfg.Combat.Profession != "" && !knownClass(cfg.Combat.Profession) {
+		return fmt.Errorf("combat.profession %q is not a known class", cfg.Combat.Profession)
+	}
+	consumables := make(map[string]bool, len(cfg.Consumables))
+	for i, rule := range cfg.Consumables {
+		if rule.Name != "" {
+			if consumables[rule.Name] {
+				return fmt.Errorf("consumables[%d].name %q is used twice", i, rule.Name)
+			}
+			consumables[rule.Name] = true
+		}
+		switch rule.Action {
+		case "use":
+			if rule.Key == "" {
+				return fmt.Errorf("consumables[%d].key is required for the use action", i)
+			}
+		case "retreat":
+		default:
+			return fmt.Errorf("consumables[%d].action %q is unknown (use or retreat)", i, rule.Action)
+		}
+	}
+
+	for i, rule := range cfg.Combat.Rotation {
+		if rule.Skill == "" {
+			return fmt.Errorf("combat.rotation[%d].skill is required", i)
+		}
+	}
+	classes := make([]string, 0, len(cfg.Combat.Classes))
+	for name := range cfg.Combat.Classes {
+		classes = append(classes, name)
+	}
+	sort.Strings(classes)
+	for _, name := range classes {
+		if !slices.Contains(classNames, name) {
+			return fmt.Errorf("combat.classes.%s is not a class name (%s)", name, strings.Join(classNames, ", "))
+		}
+	}
+
+	for i, g := range cfg.Profile.AlternateGrounds {
+		if g.MapID == "" || (g.Radius <= 0 && len(g.Areas) == 0) {
+			return fmt.Errorf("profile.alternateGrounds[%d] needs a mapId and a positive radius or areas", i)
+		}
+		if g.Weight < 0 {
+			return fmt.Errorf("profile.alternateGrounds[%d].weight must be non-negative", i)
+		}
+	}
+
+	// Every route the bot walks may hold dialog waypoints
+	type route struct {
+		path      string
+		waypoints []Waypoint
+	}
+	routes := []route{
+		{"profile.waypoints", cfg.Profile.Waypoints},
+		{"profile.huntingGround.waypoints", cfg.Profile.HuntingGround.Waypoints},
+		{"profile.town.waypoints", cfg.Profile.Town.Waypoints},
+	}
+	for i, g := range cfg.Profile.AlternateGrounds {
+		routes = append(routes, route{fmt.Sprintf("profile.alternateGrounds[%d].waypoints", i), g.Waypoints})
+	}
+	for i, q := range cfg.Profile.Quests {
+		if q.Accept != nil {
+			routes = append(routes, route{fmt.Sprintf("profile.quests[%d].accept.waypoints", i), q.Accept.Waypoints})
+		}
+		routes = append(routes, route{fmt.Sprintf("profile.quests[%d].turnIn.waypoints", i), q.TurnIn.Waypoints})
+	}
+	for _, r := range routes {
+		if err := validateRoute(r.path, r.waypoints); err != nil {
+			return err
+		}
+	}
+
+	grounds := append([]HuntingGround{cfg.Profile.HuntingGround}, cfg.Profile.AlternateGrounds...)
+	for i, g := range grounds {
+		path := "profile.huntingGround"
+		if i > 0 {
+			path = fmt.Sprintf("profile.alternateGrounds[%d]", i-1)
+		}
+		switch g.Patrol.Mode {
+		case "", "random":
+		case "loop", "pingpong", "spawns":
+			if len(g.Patrol.Points) < 2 {
+				return fmt.Errorf("%s.patrol: mode %s needs at least 2 points", path, g.Patrol.Mode)
+			}
+		default:
+			return fmt.Errorf("%s.patrol.mode %q is unknown", path, g.Patrol.Mode)
+		}
+		names := make(map[string]bool, len(g.Patrol.Points))
+		for j, pt := range g.Patrol.Points {
+			if pt.Name == "" {
+				return fmt.Errorf("%s.patrol.points[%d].name is required", path, j)
+			}
+			if names[pt.Name] {
+				return fmt.Errorf("%s.patrol.points[%d].name %q is used twice", path, j, pt.Name)
+			}
+			names[pt.Name] = true
+		}
+		for _, shapes := range []struct {
+			field string
+			list  []Shape
+		}{{"areas", g.Areas}, {"exclude", g.Exclude}} {
+			for j, shape := range shapes.list {
+				if len(shape.Polygon) > 0 && len(shape.Polygon) < 3 {
+					return fmt.Errorf("%s.%s[%d].polygon needs at least 3 points", path, shapes.field, j)
+				}
+			}
+		}
+	}
+
+	// The hero retreats on the map it hunts on, there is no route to zones elsewhere
+	groundMaps := make(map[string]bool, len(grounds))
+	for _, g := range grounds {
+		groundMaps[g.MapID] = true
+	}
+	for i, zone := range cfg.Profile.SafeZones {
+		if zone.MapID == "" {
+			return fmt.Errorf("profile.safeZones[%d].mapId is required", i)
+		}
+		if !groundMaps[zone.MapID] {
+			return fmt.Errorf("profile.safeZones[%d].mapId %q is not the map of a hunting ground", i, zone.MapID)
+		}
+		if len(zone.Polygon) > 0 && len(zone.Polygon) < 3 {
+			return fmt.Errorf("profile.safeZones[%d].polygon needs at least 3 points", i)
+		}
+	}
+
+
+	if cfg.Profile.Town.Enabled {
+		if cfg.Profile.Town.Merchant.NPCID == "" {
+			return fmt.Errorf("profile.town.merchant.npcId is required when town trips are enabled")
+		}
+		for i, item := range cfg.Profile.Town.Restock {
+			if item.Name == "" {
+				return fmt.Errorf("profile.town.restock[%d].name is required", i)
+			}
+			if item.MinQuantity > item.Quantity {
+				return fmt.Errorf("profile.town.restock[%d].minQuantity must be <= quantity", i)
+			}
+		}
+	}
+
+	for i, q := range cfg.Profile.Quests {
+		if q.Name == "" {
+			return fmt.Errorf("profile.quests[%d].name is required", i)
+		}
+		if q.TurnIn.NPCID == "" {
+			return fmt.Errorf("profile.quests[%d].turnIn.npcId is required", i)
+		}
+		for j, t := range q.Targets {
+			if t.Mob == "" || t.Count <= 0 {
+				return fmt.Errorf("profile.quests[%d].targets[%d] needs a mob and a positive count", i, j)
+			}
+		}
+	}
+
+	for i, rule := range cfg.Chat.Rules {
+		for field, pattern := range map[string]string{"author": rule.Author, "text": rule.Text, "channel": rule.Channel} {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("chat.rules[%d].%s is not a valid regex: %w", i, field, err)
+			}
+		}
+		for _, action := range rule.Actions {
+			switch action {
+			case "pause", "screenshot", "log", "notify":
+			default:
+				return fmt.Errorf("chat.rules[%d] has unknown action %q", i, action)
+			}
+		}
+	}
 
+	for _, t := range []struct {
+		field string
+		value int
+	}{
+		{"pollMs", cfg.Timing.PollMs},
+		{"battlePollMs", cfg.Timing.BattlePollMs},
+		{"idlePollMs", cfg.Timing.IdlePollMs},
+		{"combatTickMs", cfg.Timing.CombatTickMs},
+		{"patrolInterval", cfg.Timing.PatrolInterval},
+	} {
+		if t.value <= 0 {
+			return fmt.Errorf("timing.%s must be positive", t.field)
+		}
+	}
+	if cfg.Timing.StuckWindow <= 0 || cfg.Timing.StuckWindow > maxStuckWindow {
+		return fmt.Errorf("timing.stuckWindow must be between 1 and %d seconds", maxStuckWindow)
+	}
+	if cfg.Timing.StuckThreshold <= 0 {
+		return fmt.Errorf("timing.stuckThreshold must be positive")
+	}
 
 	if cfg.Behavior.MinDelayMs < 0 {
 		return fmt.Errorf("behavior.minDelayMs must be non-negative")
@@ -70,3 +312,13 @@ func validate(cfg *Config) error {
 
 	return nil
 }
+
+// validateRoute checks the waypoints of one route, path being its config key
+func validateRoute(path string, waypoints []Waypoint) error {
+	for i, wp := range waypoints {
+		if wp.Action == "dialog" && wp.NPCID == "" {
+			return fmt.Errorf("%s[%d].npcId is required for dialog waypoints", path, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies a kind of event
+type Type string
+
+const (
+	ChatMessage Type = "chat.message"
+	Stuck       Type = "nav.stuck"
+
+	// State changes seen by the game state poller, carrying the *game.Snapshot
+	MobsChanged   Type = "state.mobs"
+	BattleChanged Type = "state.battle"
+	HeroChanged   Type = "state.hero"
+)
+
+// Event is something that happened in the game or the bot
+type Event struct {
+	Type Type
+	Time time.Time
+	Data interface{}
+}
+
+// Bus delivers published events to subscribers
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[Type][]chan Event
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[Type][]chan Event),
+	}
+}
+
+// Subscribe returns a channel receiving events of the given type
+func (b *Bus) Subscribe(t Type, buffer int) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, buffer)
+	b.subs[t] = append(b.subs[t], ch)
+	return ch
+}
+
+// Publish sends an event to all subscribers of its type. Subscribers that
+// are not keeping up miss the event rather than blocking the publisher.
+func (b *Bus) Publish(t Type, data interface{}) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	e := Event{Type: t, Time: time.Now(), Data: data}
+	for _, ch := range b.subs[t] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed js
+var scripts embed.FS
+
+// Operations every adapter implements, one JS file each. Operations that
+// work the same everywhere live in js/common and need no adapter.
+const (
+	opDetect      = "detect"
+	opHero        = "hero"
+	opMobs        = "mobs"
+	opPlayers     = "players"
+	opMove        = "move"
+	opAttack      = "attack"
+	opBattle      = "battle"
+	opConnected   = "connected"
+	opCamera      = "camera"
+	opSkills      = "skills"
+	opBattleState = "battle_state"
+	opItems       = "items"
+	opTalk        = "talk"
+	opDialog      = "dialog"
+	opShopOpen    = "shop_open"
+	opQuests      = "quests"
+	opChat        = "chat"
+	opRespawn     = "respawn"
+
+	opLoot         = "loot"
+	opRequest      = "request"
+	opChooseOption = "choose_option"
+	opLoggedIn     = "logged_in"
+	opLogin        = "login"
+)
+
+// EngineAdapter maps one version of the game interface. Field mappings live
+// in the adapter's JS files, so a game update only needs a new adapter.
+type EngineAdapter interface {
+	// Name identifies the adapter in logs
+	Name() string
+	// Script returns the JS function implementing an operation
+	Script(op string) (string, error)
+}
+
+// scriptAdapter serves an adapter's scripts from its embedded directory
+type scriptAdapter struct {
+	name string
+	dir  string
+}
+
+func (a *scriptAdapter) Name() string {
+	return a.name
+}
+
+func (a *scriptAdapter) Script(op string) (string, error) {
+	src, err := scripts.ReadFile("js/" + a.dir + "/" + op + ".js")
+	if err != nil {
+		return "", fmt.Errorf("%s adapter has no %s script: %w", a.name, op, err)
+	}
+	return string(src), nil
+}
+
+// ClassicAdapter drives the classic interface (window.hero, window.g, window.map)
+type ClassicAdapter struct {
+	scriptAdapter
+}
+
+// NewClassicAdapter creates the classic interface adapter
+func NewClassicAdapter() *ClassicAdapter {
+	return &ClassicAdapter{scriptAdapter{name: "classic", dir: "classic"}}
+}
+
+// EngineInterfaceAdapter drives the newer interface built around window.Engine
+type EngineInterfaceAdapter struct {
+	scriptAdapter
+}
+
+// NewEngineInterfaceAdapter creates the newer interface adapter
+func NewEngineInterfaceAdapter() *EngineInterfaceAdapter {
+	return &EngineInterfaceAdapter{scriptAdapter{name: "engine", dir: "engine"}}
+}
+
+// adapters lists the known interfaces in detection order. The newer
+// interface comes first as it may keep some classic globals around.
+func adapters() []EngineAdapter {
+	return []EngineAdapter{
+		NewEngineInterfaceAdapter(),
+		NewClassicAdapter(),
+	}
+}
+
+// detectAdapter returns the adapter whose interface is loaded, if any
+func (c *Client) detectAdapter() EngineAdapter {
+	for _, a := range adapters() {
+		var found bool
+		if err := c.evalAdapter(a, opDetect, &found); err == nil && found {
+			return a
+		}
+	}
+	return nil
+}
+
+// Adapter returns the detected game interface adapter (nil before EnsureReady)
+func (c *Client) Adapter() EngineAdapter {
+	return c.adapter
+}
+
+// script returns the JS function for an operation with the detected adapter
+func (c *Client) script(op string) (string, error) {
+	return adapterScript(c.adapter, op)
+}
+
+// adapterScript returns the adapter's own version of an operation when it
+// has one, the shared version from js/common otherwise
+func adapterScript(a EngineAdapter, op string) (string, error) {
+	if a != nil {
+		if src, err := a.Script(op); err == nil {
+			return src, nil
+		}
+	}
+
+	src, err := scripts.ReadFile("js/common/" + op + ".js")
+	if err != nil {
+		return "", fmt.Errorf("no %s script for this game interface", op)
+	}
+	return string(src), nil
+}
+
+// call runs a script with its arguments passed as JSON values
+func (c *Client) call(op string, res interface{}, args ...interface{}) error {
+	fn, err := c.script(op)
+	if err != nil {
+		return err
+	}
+	return c.browser.Call(fn, res, args...)
+}
+
+func (c *Client) evalAdapter(a EngineAdapter, op string, res interface{}) error {
+	fn, err := a.Script(op)
+	if err != nil {
+		return err
+	}
+	return c.browser.Call(fn, res)
+}
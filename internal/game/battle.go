@@ -0,0 +1,157 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Skill is a battle skill the hero knows
+type Skill struct {
+	ID       string
+	Name     string
+	Cooldown int // turns until the skill can be used again
+	ManaCost int
+}
+
+// Ready reports whether the skill is off cooldown
+func (s *Skill) Ready() bool {
+	return s.Cooldown <= 0
+}
+
+// Battle is the state of a running battle
+type Battle struct {
+	Active  bool
+	MyTurn  bool
+	Auto    bool // the game's auto-fight is on
+	Enemies []*Enemy
+}
+
+// Enemy is a living opponent in a battle
+type Enemy struct {
+	ID        string // battle ID, not the map mob ID
+	Name      string
+	HPPercent int
+}
+
+// skillResult is one entry of the skills script result
+type skillResult struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Cooldown int    `json:"cooldown,omitempty"`
+	ManaCost int    `json:"manaCost,omitempty"`
+}
+
+// battleResult is what the battle state script returns
+type battleResult struct {
+	Active  bool            `json:"active"`
+	MyTurn  bool            `json:"myTurn,omitempty"`
+	Auto    bool            `json:"auto,omitempty"`
+	Enemies json.RawMessage `json:"enemies,omitempty"`
+}
+
+// enemyResult is one enemy in the battle state script result
+type enemyResult struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	HPPercent int    `json:"hpPercent"`
+}
+
+// GetSkills retrieves the hero's battle skills
+func (c *Client) GetSkills() ([]*Skill, error) {
+	var raw json.RawMessage
+	if err := c.call(opSkills, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get skills: %w", err)
+	}
+	if string(raw) == "null" {
+		return nil, fmt.Errorf("skills not readable")
+	}
+
+	result, report := decodeList[skillResult](opSkills, raw)
+	c.recordList(report)
+
+	skills := make([]*Skill, 0, len(result))
+	for _, s := range result {
+		skills = append(skills, &Skill{
+			ID:       s.ID,
+			Name:     s.Name,
+			Cooldown: s.Cooldown,
+			ManaCost: s.ManaCost,
+		})
+	}
+
+	return skills, nil
+}
+
+// GetBattle reads the running battle
+func (c *Client) GetBattle() (*Battle, error) {
+	var raw json.RawMessage
+	if err := c.call(opBattleState, &raw); err != nil {
+		return nil, fmt.Errorf("failed to read battle: %w", err)
+	}
+	if string(raw) == "null" {
+		return nil, fmt.Errorf("battle not readable")
+	}
+
+	var result battleResult
+	report := decodeStrict(opBattleState, raw, &result)
+	c.record(report)
+	if !report.OK() {
+		return nil, report
+	}
+
+	battle := &Battle{
+		Active: result.Active,
+		MyTurn: result.MyTurn,
+		Auto:   result.Auto,
+	}
+	if len(result.Enemies) > 0 {
+		enemies, report := decodeList[enemyResult](opBattleState+".enemies", result.Enemies)
+		c.recordList(report)
+		for _, e := range enemies {
+			battle.Enemies = append(battle.Enemies, &Enemy{
+				ID:        e.ID,
+				Name:      e.Name,
+				HPPercent: e.HPPercent,
+			})
+		}
+	}
+
+	return battle, nil
+}
+
+// UseSkill uses a skill on a battle enemy
+func (c *Client) UseSkill(skillID, enemyID string) error {
+	c.log.WithFields(logrus.Fields{
+		"skill": skillID,
+		"enemy": enemyID,
+	}).Debug("Using skill...")
+
+	return c.battleRequest("fight&a=skill&s=" + skillID + "&id=" + enemyID)
+}
+
+// BattleAttack uses the default attack on a battle enemy
+func (c *Client) BattleAttack(enemyID string) error {
+	c.log.WithField("enemy", enemyID).Debug("Attacking in battle...")
+	return c.battleRequest("fight&a=attack&id=" + enemyID)
+}
+
+// ToggleAutoFight switches the game's built-in auto-fight on or off
+func (c *Client) ToggleAutoFight() error {
+	c.log.Debug("Toggling auto-fight...")
+	return c.battleRequest("fight&a=f")
+}
+
+// battleRequest sends a battle command to the game
+func (c *Client) battleRequest(request string) error {
+	var success bool
+	if err := c.call(opRequest, &success, request); err != nil {
+		return fmt.Errorf("battle request failed: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("battle request not supported")
+	}
+
+	return nil
+}
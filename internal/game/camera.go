@@ -0,0 +1,155 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultTileSize is the tile edge in pixels when the game doesn't say
+const defaultTileSize = 32.0
+
+// Camera maps game coordinates to viewport pixels for clicking, taking the
+// scrolled map into account. Game coordinates are map pixels, the same unit
+// as hero and mob positions, tolerances and radii everywhere in the bot.
+type Camera struct {
+	// Canvas bounding box in viewport pixels
+	Left   float64
+	Top    float64
+	Width  float64
+	Height float64
+
+	// Viewport position of the map's top-left corner
+	OriginX float64
+	OriginY float64
+
+	TileSize  float64 // only used to size the map
+	MapWidth  int     // in tiles, 0 when unknown
+	MapHeight int
+}
+
+// cameraResult is what the camera script returns
+type cameraResult struct {
+	Left      float64 `json:"left"`
+	Top       float64 `json:"top"`
+	Width     float64 `json:"width"`
+	Height    float64 `json:"height"`
+	OriginX   float64 `json:"originX"`
+	OriginY   float64 `json:"originY"`
+	TileSize  float64 `json:"tileSize,omitempty"`
+	MapWidth  int     `json:"mapWidth,omitempty"`
+	MapHeight int     `json:"mapHeight,omitempty"`
+}
+
+// GetCamera reads the current map scroll, tile size and canvas bounds
+func (c *Client) GetCamera() (*Camera, error) {
+	var raw json.RawMessage
+	if err := c.call(opCamera, &raw); err != nil {
+		return nil, fmt.Errorf("failed to read camera: %w", err)
+	}
+	if string(raw) == "null" {
+		return nil, fmt.Errorf("game canvas not found")
+	}
+
+	var result cameraResult
+	report := decodeStrict(opCamera, raw, &result)
+	c.record(report)
+	if !report.OK() {
+		return nil, report
+	}
+
+	cam := &Camera{
+		Left:      result.Left,
+		Top:       result.Top,
+		Width:     result.Width,
+		Height:    result.Height,
+		OriginX:   result.OriginX,
+		OriginY:   result.OriginY,
+		TileSize:  result.TileSize,
+		MapWidth:  result.MapWidth,
+		MapHeight: result.MapHeight,
+	}
+	if cam.TileSize <= 0 {
+		cam.TileSize = defaultTileSize
+	}
+
+	return cam, nil
+}
+
+// ToViewport converts game coordinates to a viewport pixel. Points off
+// screen are clamped to the canvas edge, visible reports whether clamping
+// was needed.
+func (cam *Camera) ToViewport(x, y float64) (vx, vy float64, visible bool) {
+	x, y = cam.clampToMap(x, y)
+
+	vx = cam.OriginX + x
+	vy = cam.OriginY + y
+
+	visible = vx >= cam.Left && vx < cam.Left+cam.Width &&
+		vy >= cam.Top && vy < cam.Top+cam.Height
+
+	// Keep a pixel inside so clicks never miss the canvas
+	vx = clamp(vx, cam.Left, cam.Left+cam.Width-1)
+	vy = clamp(vy, cam.Top, cam.Top+cam.Height-1)
+	return vx, vy, visible
+}
+
+// ToWorld converts a viewport pixel to game coordinates, clamped to the map
+func (cam *Camera) ToWorld(vx, vy float64) (x, y float64) {
+	return cam.clampToMap(vx-cam.OriginX, vy-cam.OriginY)
+}
+
+// clampToMap keeps coordinates inside the map when its size is known
+func (cam *Camera) clampToMap(x, y float64) (float64, float64) {
+	x = math.Max(0, x)
+	y = math.Max(0, y)
+	if cam.MapWidth > 0 {
+		x = math.Min(x, float64(cam.MapWidth)*cam.TileSize-1)
+	}
+	if cam.MapHeight > 0 {
+		y = math.Min(y, float64(cam.MapHeight)*cam.TileSize-1)
+	}
+	return x, y
+}
+
+// ClickWorld clicks the map at game coordinates x/y
+func (c *Client) ClickWorld(x, y float64) error {
+	vx, vy, err := c.viewportPoint(x, y)
+	if err != nil {
+		return err
+	}
+	return c.browser.ClickAt(vx, vy)
+}
+
+// DoubleClickWorld double-clicks the map at game coordinates x/y
+func (c *Client) DoubleClickWorld(x, y float64) error {
+	vx, vy, err := c.viewportPoint(x, y)
+	if err != nil {
+		return err
+	}
+	return c.browser.DoubleClickAt(vx, vy)
+}
+
+// viewportPoint projects game coordinates with the current camera
+func (c *Client) viewportPoint(x, y float64) (float64, float64, error) {
+	cam, err := c.GetCamera()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	vx, vy, visible := cam.ToViewport(x, y)
+	if !visible {
+		// Clicking the canvas edge still walks toward the target
+		c.log.WithFields(logrus.Fields{
+			"x": x,
+			"y": y,
+		}).Debug("Target off screen, clicking canvas edge")
+	}
+	return vx, vy, nil
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,115 @@
+package game
+
+import "testing"
+
+// testCamera is a 800x600 canvas at 100/50 scrolled 200/100 pixels into a
+// 50x40 tile map
+func testCamera() *Camera {
+	return &Camera{
+		Left:      100,
+		Top:       50,
+		Width:     800,
+		Height:    600,
+		OriginX:   -100,
+		OriginY:   -50,
+		TileSize:  32,
+		MapWidth:  50,
+		MapHeight: 40,
+	}
+}
+
+func TestToViewportOnScreen(t *testing.T) {
+	cam := testCamera()
+
+	vx, vy, visible := cam.ToViewport(500, 300)
+	if !visible {
+		t.Fatal("point on screen reported as off screen")
+	}
+	if vx != 400 || vy != 250 {
+		t.Errorf("ToViewport(500, 300) = %v, %v; want 400, 250", vx, vy)
+	}
+}
+
+func TestToViewportClampsToCanvas(t *testing.T) {
+	cam := testCamera()
+
+	tests := []struct {
+		name   string
+		x, y   float64
+		vx, vy float64
+	}{
+		{"left of screen", 50, 300, 100, 250},
+		{"above screen", 500, 10, 400, 50},
+		{"right of screen", 1500, 300, 899, 250},
+		{"below screen", 500, 1200, 400, 649},
+		{"past both edges", 1500, 1200, 899, 649},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vx, vy, visible := cam.ToViewport(tt.x, tt.y)
+			if visible {
+				t.Error("point off screen reported as visible")
+			}
+			if vx != tt.vx || vy != tt.vy {
+				t.Errorf("ToViewport(%v, %v) = %v, %v; want %v, %v", tt.x, tt.y, vx, vy, tt.vx, tt.vy)
+			}
+		})
+	}
+}
+
+func TestToViewportClampsToMap(t *testing.T) {
+	cam := testCamera()
+	// Show the whole map so only the map bounds clamp
+	cam.OriginX, cam.OriginY = cam.Left, cam.Top
+	cam.Width, cam.Height = 2000, 2000
+
+	tests := []struct {
+		name   string
+		x, y   float64
+		vx, vy float64
+	}{
+		{"negative", -40, -10, 100, 50},
+		{"past the right edge", 5000, 100, 100 + 50*32 - 1, 150},
+		{"past the bottom edge", 100, 5000, 200, 50 + 40*32 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vx, vy, visible := cam.ToViewport(tt.x, tt.y)
+			if !visible {
+				t.Error("clamped point reported as off screen")
+			}
+			if vx != tt.vx || vy != tt.vy {
+				t.Errorf("ToViewport(%v, %v) = %v, %v; want %v, %v", tt.x, tt.y, vx, vy, tt.vx, tt.vy)
+			}
+		})
+	}
+}
+
+func TestToViewportUnknownMapSize(t *testing.T) {
+	cam := testCamera()
+	cam.MapWidth, cam.MapHeight = 0, 0
+
+	vx, vy, _ := cam.ToViewport(5000, 5000)
+	if vx != 899 || vy != 649 {
+		t.Errorf("ToViewport(5000, 5000) = %v, %v; want the canvas corner", vx, vy)
+	}
+}
+
+func TestToWorld(t *testing.T) {
+	cam := testCamera()
+
+	x, y := cam.ToWorld(400, 250)
+	if x != 500 || y != 300 {
+		t.Errorf("ToWorld(400, 250) = %v, %v; want 500, 300", x, y)
+	}
+
+	x, y = cam.ToWorld(-500, -500)
+	if x != 0 || y != 0 {
+		t.Errorf("ToWorld(-500, -500) = %v, %v; want the map corner", x, y)
+	}
+
+	x, y = cam.ToWorld(5000, 5000)
+	if x != 50*32-1 || y != 40*32-1 {
+		t.Errorf("ToWorld(5000, 5000) = %v, %v; want the far map corner", x, y)
+	}
+}
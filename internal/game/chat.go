@@ -0,0 +1,60 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChatMessage is a line from the game chat
+type ChatMessage struct {
+	Time    time.Time
+	Channel string // "local", "global", "clan", "group", "private", "system"
+	Author  string
+	Text    string
+}
+
+// GetChatMessages retrieves chat messages stamped at or after since, in the
+// page's own time. The zero time returns the whole chat log.
+func (c *Client) GetChatMessages(since time.Time) ([]ChatMessage, error) {
+	var result []struct {
+		TS      int64  `json:"ts"`
+		Channel string `json:"channel"`
+		Author  string `json:"author"`
+		Text    string `json:"text"`
+	}
+	if err := c.call(opChat, &result, since.UnixMilli()); err != nil {
+		return nil, fmt.Errorf("failed to get chat messages: %w", err)
+	}
+
+	messages := make([]ChatMessage, 0, len(result))
+	for _, r := range result {
+		messages = append(messages, ChatMessage{
+			Time:    time.UnixMilli(r.TS),
+			Channel: channelName(r.Channel),
+			Author:  r.Author,
+			Text:    r.Text,
+		})
+	}
+
+	return messages, nil
+}
+
+// channelName maps the game's numeric chat channels to names
+func channelName(channel string) string {
+	switch channel {
+	case "0":
+		return "local"
+	case "1":
+		return "global"
+	case "2":
+		return "clan"
+	case "3":
+		return "group"
+	case "4":
+		return "private"
+	case "5":
+		return "system"
+	default:
+		return channel
+	}
+}
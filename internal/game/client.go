@@ -3,6 +3,7 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/kamilkurek/margonem-bot/internal/browser"
@@ -11,39 +12,41 @@ import (
 
 // Client handles game-specific operations
 type Client struct {
-	browser *browser.Controller
-	log     *logrus.Logger
+	browser        *browser.Controller
+	stateMgr       *StateManager
+	log            *logrus.Logger
+	adapter        EngineAdapter
+	attackStrategy string
+	health         adapterHealth
+	seq            atomic.Uint64
+	pollInterval   atomic.Int64 // state poll interval, see SetPollInterval
+	snapshotFn     string
+	snapshotFor    string
 }
 
-// NewClient creates a new game client
-func NewClient(browser *browser.Controller, log *logrus.Logger) *Client {
+// NewClient creates a new game client; the state manager is used to watch
+// the hero while waiting for actions to complete
+func NewClient(browser *browser.Controller, stateMgr *StateManager, log *logrus.Logger) *Client {
 	return &Client{
-		browser: browser,
-		log:     log,
+		browser:  browser,
+		stateMgr: stateMgr,
+		log:      log,
 	}
 }
 
-// EnsureReady waits for the game engine to be ready
+// EnsureReady waits for the game engine to be ready and detects which
+// interface version it runs
 func (c *Client) EnsureReady() error {
 	c.log.Info("Waiting for game engine to be ready...")
 	
-	script := `
-	(function() {
-		return (window.hero !== undefined && window.hero !== null) || 
-		       (window.Hero !== undefined && window.Hero !== null) ||
-		       (window.g && window.g.hero !== undefined) ||
-		       (window.Engine !== undefined);
-	})()
-	`
-	
 	// Wait up to 30 seconds for game to be ready
 	timeout := 30 * time.Second
 	start := time.Now()
 	
 	for time.Since(start) < timeout {
-		var ready bool
-		if err := c.browser.Eval(script, &ready); err == nil && ready {
-			c.log.Info("Game engine is ready!")
+		if adapter := c.detectAdapter(); adapter != nil {
+			c.adapter = adapter
+			c.log.WithField("interface", adapter.Name()).Info("Game engine is ready!")
 			return nil
 		}
 		time.Sleep(500 * time.Millisecond)
@@ -52,115 +55,153 @@ func (c *Client) EnsureReady() error {
 	return fmt.Errorf("game engine not ready after %v", timeout)
 }
 
+// heroResult is what the hero script returns; omitempty marks optional fields
+type heroResult struct {
+	X        float64         `json:"x"`
+	Y        float64         `json:"y"`
+	MapID    string          `json:"mapId"`
+	Nick     string          `json:"nick,omitempty"`
+	HP       int             `json:"hp"`
+	HPMax    int             `json:"hpMax"`
+	MP       int             `json:"mp,omitempty"`
+	MPMax    int             `json:"mpMax,omitempty"`
+	Level    int             `json:"level"`
+	Prof     string          `json:"profession,omitempty"`
+	Effects  json.RawMessage `json:"effects,omitempty"`
+	Exp      *int            `json:"exp,omitempty"`
+	ExpNext  int             `json:"expNext,omitempty"`
+	Gold     int             `json:"gold,omitempty"`
+	InCombat bool            `json:"inCombat,omitempty"`
+	Dead     bool            `json:"dead,omitempty"`
+}
+
 // GetHeroState retrieves the current hero state from the game
 func (c *Client) GetHeroState() (*HeroState, error) {
-	script := `
-	(function() {
-		try {
-			let hero = window.hero || window.Hero || (window.g && window.g.hero);
-			if (!hero) return null;
-			
-			return {
-				x: hero.x || hero.posX || 0,
-				y: hero.y || hero.posY || 0,
-				mapId: (hero.map && hero.map.id) || hero.mapId || "",
-				hp: hero.hp || hero.HP || 0,
-				hpMax: hero.maxhp || hero.maxHP || hero.hpMax || 100,
-				mp: hero.mp || hero.MP || 0,
-				mpMax: hero.maxmp || hero.maxMP || hero.mpMax || 100,
-				level: hero.lvl || hero.level || 1,
-				exp: hero.exp || 0,
-				inCombat: hero.inCombat || hero.incombat || false,
-				dead: hero.dead || hero.isDead || hero.hp <= 0
-			};
-		} catch(e) {
-			console.error("Error getting hero state:", e);
-			return null;
-		}
-	})()
-	`
-	
-	var result map[string]interface{}
-	if err := c.browser.Eval(script, &result); err != nil {
+	var raw json.RawMessage
+	if err := c.call(opHero, &raw); err != nil {
 		return nil, fmt.Errorf("failed to get hero state: %w", err)
 	}
 	
-	if result == nil {
+	return c.decodeHero(raw)
+}
+
+// decodeHero turns a hero script result into a HeroState
+func (c *Client) decodeHero(raw json.RawMessage) (*HeroState, error) {
+	if len(raw) == 0 || string(raw) == "null" {
 		return nil, fmt.Errorf("hero object not found")
 	}
 	
+	var result heroResult
+	report := decodeStrict(opHero, raw, &result)
+	c.record(report)
+	if !report.OK() {
+		return nil, report
+	}
+	
 	state := &HeroState{
-		X:        getFloat(result, "x"),
-		Y:        getFloat(result, "y"),
-		MapID:    getString(result, "mapId"),
-		HP:       getInt(result, "hp"),
-		HPMax:    getInt(result, "hpMax"),
-		MP:       getInt(result, "mp"),
-		MPMax:    getInt(result, "mpMax"),
-		Level:    getInt(result, "level"),
-		Exp:      getInt(result, "exp"),
-		InCombat: getBool(result, "inCombat"),
-		Dead:     getBool(result, "dead"),
+		X:          result.X,
+		Y:          result.Y,
+		MapID:      result.MapID,
+		Nick:       result.Nick,
+		HP:         result.HP,
+		HPMax:      result.HPMax,
+		MP:         result.MP,
+		MPMax:      result.MPMax,
+		Level:      result.Level,
+		Profession: ParseProfession(result.Prof),
+		ExpNext:    result.ExpNext,
+		Gold:       result.Gold,
+		InCombat:   result.InCombat,
+		Dead:       result.Dead,
+	}
+	if result.Exp != nil {
+		state.Exp, state.ExpKnown = *result.Exp, true
+	}
+	
+	// Effects are optional: unreadable ones are dropped without marking
+	// the adapter broken, as a single odd buff shouldn't hold the bot
+	if len(result.Effects) > 0 {
+		effects, report := decodeList[effectResult](opHero+".effects", result.Effects)
+		if !report.OK() {
+			c.log.WithError(report).Debug("Dropped unreadable effects")
+		}
+		for _, e := range effects {
+			state.Effects = append(state.Effects, Effect{
+				Name:      e.Name,
+				Remaining: time.Duration(e.Remaining * float64(time.Second)),
+				Stacks:    e.Stacks,
+				Debuff:    e.Debuff,
+			})
+		}
 	}
 	
 	return state, nil
 }
 
-// GetMobs retrieves nearby mobs from the game
+// effectResult is one buff or debuff in the hero script result
+type effectResult struct {
+	Name      string  `json:"name"`
+	Remaining float64 `json:"remaining,omitempty"` // seconds
+	Stacks    int     `json:"stacks,omitempty"`
+	Debuff    bool    `json:"debuff,omitempty"`
+}
+
+// mobResult is one entry of the mobs script result
+type mobResult struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Level      int     `json:"level,omitempty"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	HP         int     `json:"hp,omitempty"`
+	HPMax      int     `json:"hpMax,omitempty"`
+	Alive      bool    `json:"alive"`
+	Attackable bool    `json:"attackable"`
+	Aggressive bool    `json:"aggressive,omitempty"`
+}
+
+// GetMobs retrieves nearby mobs from the game. Mobs lacking required
+// fields are left out, see recordList.
 func (c *Client) GetMobs() ([]*Mob, error) {
-	script := `
-	(function() {
-		try {
-			let npcList = window.npcs || window.NPC || (window.g && window.g.npcs) || [];
-			let mobs = [];
-			
-			for (let id in npcList) {
-				let npc = npcList[id];
-				if (!npc || npc.type !== 1) continue; // type 1 = monster
-				
-				mobs.push({
-					id: id,
-					name: npc.nick || npc.name || "",
-					level: npc.lvl || npc.level || 1,
-					x: npc.x || npc.posX || 0,
-					y: npc.y || npc.posY || 0,
-					hp: npc.hp || 0,
-					hpMax: npc.maxhp || npc.hpMax || 100,
-					alive: !npc.dead && npc.hp > 0,
-					attackable: !npc.dead && npc.hp > 0
-				});
-			}
-			
-			return mobs;
-		} catch(e) {
-			console.error("Error getting mobs:", e);
-			return [];
-		}
-	})()
-	`
-	
-	var result []map[string]interface{}
-	if err := c.browser.Eval(script, &result); err != nil {
+	var raw json.RawMessage
+	if err := c.call(opMobs, &raw); err != nil {
 		return nil, fmt.Errorf("failed to get mobs: %w", err)
 	}
 	
+	return c.decodeMobs(raw), nil
+}
+
+// decodeMobs turns a mobs script result into Mobs
+func (c *Client) decodeMobs(raw json.RawMessage) []*Mob {
+	result, report := decodeList[mobResult](opMobs, raw)
+	c.recordList(report)
+	
 	mobs := make([]*Mob, 0, len(result))
 	for _, m := range result {
 		mob := &Mob{
-			ID:         getString(m, "id"),
-			Name:       getString(m, "name"),
-			Level:      getInt(m, "level"),
-			X:          getFloat(m, "x"),
-			Y:          getFloat(m, "y"),
-			HP:         getInt(m, "hp"),
-			HPMax:      getInt(m, "hpMax"),
-			Alive:      getBool(m, "alive"),
-			Attackable: getBool(m, "attackable"),
+			ID:         m.ID,
+			Name:       m.Name,
+			Level:      m.Level,
+			X:          m.X,
+			Y:          m.Y,
+			HP:         m.HP,
+			HPMax:      m.HPMax,
+			Alive:      m.Alive,
+			Attackable: m.Attackable,
+			Aggressive: m.Aggressive,
 		}
 		mobs = append(mobs, mob)
 	}
 	
-	return mobs, nil
+	return mobs
+}
+
+// ClickElement clicks a page element, e.g. a portal drawn outside the canvas
+func (c *Client) ClickElement(selector string) error {
+	if err := c.browser.Click(selector); err != nil {
+		return fmt.Errorf("failed to click %s: %w", selector, err)
+	}
+	return nil
 }
 
 // MoveTo moves the hero to specific coordinates
@@ -170,84 +211,121 @@ func (c *Client) MoveTo(x, y float64) error {
 		"y": y,
 	}).Debug("Moving hero...")
 	
-	// Try to use game API first
-	script := fmt.Sprintf(`
-	(function() {
-		try {
-			if (window.hero && window.hero.moveTo) {
-				window.hero.moveTo(%f, %f);
-				return true;
-			}
-			if (window.Engine && window.Engine.moveHero) {
-				window.Engine.moveHero(%f, %f);
-				return true;
-			}
-			return false;
-		} catch(e) {
-			console.error("Error moving:", e);
-			return false;
-		}
-	})()
-	`, x, y, x, y)
+	// Remember where we are heading for stuck detection
+	c.stateMgr.SetMoveGoal(x, y)
 	
+	// Try to use game API first
 	var success bool
-	if err := c.browser.Eval(script, &success); err != nil {
+	if err := c.call(opMove, &success, x, y); err != nil {
 		return fmt.Errorf("failed to move: %w", err)
 	}
 	
 	if !success {
-		// Fallback: click on map at coordinates
-		return c.browser.ClickAt(x, y)
+		// Fallback: click the tile on the map
+		return c.ClickWorld(x, y)
 	}
 	
 	return nil
 }
 
-// AttackMob attacks a specific mob
+// Click attack strategies; API strategies are named by the adapter scripts
+// so the engine variant in use is visible in the logs
+const (
+	AttackClick       = "click"
+	AttackDoubleClick = "double-click"
+)
+
+// battleStartTimeout is how long a click attack gets to open a battle
+const battleStartTimeout = 2 * time.Second
+
+// attackResult is what the attack script returns: the strategy used, or
+// the mob position for the click fallback
+type attackResult struct {
+	Found    bool    `json:"found"`
+	Strategy string  `json:"strategy,omitempty"`
+	X        float64 `json:"x,omitempty"`
+	Y        float64 `json:"y,omitempty"`
+}
+
+// AttackMob attacks a specific mob, through the game API when there is one
+// and by clicking the mob on the map otherwise
 func (c *Client) AttackMob(mobID string) error {
 	c.log.WithField("mobId", mobID).Debug("Attacking mob...")
 	
-	script := fmt.Sprintf(`
-	(function() {
-		try {
-			let npcList = window.npcs || window.NPC || (window.g && window.g.npcs);
-			let target = npcList['%s'];
-			
-			if (!target) return false;
-			
-			if (window.hero && window.hero.attack) {
-				window.hero.attack(target);
-				return true;
-			}
-			if (window.Engine && window.Engine.attack) {
-				window.Engine.attack(target);
-				return true;
-			}
-			
-			// Try clicking on mob
-			if (target.x && target.y) {
-				// This will be handled by click fallback
-				return false;
-			}
-			
-			return false;
-		} catch(e) {
-			console.error("Error attacking:", e);
-			return false;
-		}
-	})()
-	`, mobID)
-	
-	var success bool
-	if err := c.browser.Eval(script, &success); err != nil {
+	var raw json.RawMessage
+	if err := c.call(opAttack, &raw, mobID); err != nil {
 		return fmt.Errorf("failed to attack: %w", err)
 	}
 	
-	if !success {
-		return fmt.Errorf("could not attack mob")
+	var result attackResult
+	if report := decodeStrict(opAttack, raw, &result); !report.OK() {
+		return fmt.Errorf("failed to attack: %w", report)
 	}
 	
-	return nil
+	if !result.Found {
+		return fmt.Errorf("mob %s not found", mobID)
+	}
+	
+	if result.Strategy != "" {
+		c.reportAttackStrategy(result.Strategy)
+		return nil
+	}
+	
+	// Click fallback: a single click on the sprite attacks in most
+	// versions, some need a double click
+	x, y := result.X, result.Y
+	if err := c.ClickWorld(x, y); err != nil {
+		return fmt.Errorf("failed to click mob: %w", err)
+	}
+	if c.waitForBattle(battleStartTimeout) {
+		c.reportAttackStrategy(AttackClick)
+		return nil
+	}
+	
+	if err := c.DoubleClickWorld(x, y); err != nil {
+		return fmt.Errorf("failed to double-click mob: %w", err)
+	}
+	if c.waitForBattle(battleStartTimeout) {
+		c.reportAttackStrategy(AttackDoubleClick)
+		return nil
+	}
+	
+	return fmt.Errorf("could not attack mob: no battle after clicking it")
+}
+
+// AttackStrategy returns the attack strategy that worked last
+func (c *Client) AttackStrategy() string {
+	return c.attackStrategy
+}
+
+// reportAttackStrategy logs the attack strategy whenever it changes
+func (c *Client) reportAttackStrategy(strategy string) {
+	if strategy == c.attackStrategy {
+		return
+	}
+	c.log.WithField("strategy", strategy).Info("Attack strategy in use")
+	c.attackStrategy = strategy
+}
+
+// InBattle checks whether a battle is going on
+func (c *Client) InBattle() (bool, error) {
+	var inBattle bool
+	if err := c.call(opBattle, &inBattle); err != nil {
+		return false, err
+	}
+	return inBattle, nil
+}
+
+// waitForBattle polls until a battle starts or the timeout passes
+func (c *Client) waitForBattle(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if inBattle, err := c.InBattle(); err == nil && inBattle {
+			return true
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return false
 }
 
 // UsePotion uses a potion via hotkey
@@ -256,40 +334,19 @@ func (c *Client) UsePotion(key string) error {
 	return c.browser.PressKey(key)
 }
 
+// Sit makes the hero sit down via hotkey to regenerate faster
+func (c *Client) Sit(key string) error {
+	c.log.WithField("key", key).Debug("Sitting down...")
+	return c.browser.PressKey(key)
+}
+
 // Respawn clicks the respawn button when dead
 func (c *Client) Respawn() error {
 	c.log.Info("Attempting to respawn...")
 	
 	// Try to find and click respawn button
-	script := `
-	(function() {
-		try {
-			// Look for common respawn button selectors
-			let btn = document.querySelector('.respawn-button') ||
-			          document.querySelector('#respawn') ||
-			          document.querySelector('[data-action="respawn"]');
-			          
-			if (btn) {
-				btn.click();
-				return true;
-			}
-			
-			// Try game API
-			if (window.hero && window.hero.respawn) {
-				window.hero.respawn();
-				return true;
-			}
-			
-			return false;
-		} catch(e) {
-			console.error("Error respawning:", e);
-			return false;
-		}
-	})()
-	`
-	
 	var success bool
-	if err := c.browser.Eval(script, &success); err != nil {
+	if err := c.call(opRespawn, &success); err != nil {
 		return fmt.Errorf("failed to respawn: %w", err)
 	}
 	
@@ -303,82 +360,14 @@ func (c *Client) Respawn() error {
 
 // IsConnected checks if the game is still connected
 func (c *Client) IsConnected() (bool, error) {
-	script := `
-	(function() {
-		try {
-			// Check if socket/connection is alive
-			if (window.connection && window.connection.connected !== undefined) {
-				return window.connection.connected;
-			}
-			if (window.socket && window.socket.connected !== undefined) {
-				return window.socket.connected;
-			}
-			if (window.ws && window.ws.readyState !== undefined) {
-				return window.ws.readyState === 1; // WebSocket.OPEN
-			}
-			// Assume connected if hero exists
-			return window.hero !== undefined && window.hero !== null;
-		} catch(e) {
-			return false;
-		}
-	})()
-	`
-	
 	var connected bool
-	if err := c.browser.Eval(script, &connected); err != nil {
+	if err := c.call(opConnected, &connected); err != nil {
 		return false, err
 	}
 	
 	return connected, nil
 }
 
-// Helper functions to safely extract values from map
-func getFloat(m map[string]interface{}, key string) float64 {
-	if v, ok := m[key]; ok {
-		switch val := v.(type) {
-		case float64:
-			return val
-		case int:
-			return float64(val)
-		case int64:
-			return float64(val)
-		}
-	}
-	return 0
-}
-
-func getInt(m map[string]interface{}, key string) int {
-	if v, ok := m[key]; ok {
-		switch val := v.(type) {
-		case float64:
-			return int(val)
-		case int:
-			return val
-		case int64:
-			return int(val)
-		}
-	}
-	return 0
-}
-
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
-		}
-	}
-	return ""
-}
-
-func getBool(m map[string]interface{}, key string) bool {
-	if v, ok := m[key]; ok {
-		if b, ok := v.(bool); ok {
-			return b
-		}
-	}
-	return false
-}
-
 // Dump for debugging
 func (c *Client) DumpGameState() (string, error) {
 	hero, err := c.GetHeroState()
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldReport lists the fields a script result lacked or returned with the
+// wrong type. It doubles as the error returned for unusable results.
+type FieldReport struct {
+	Op      string
+	Missing []string
+	Invalid []string
+	Dropped int // list elements left out for missing or invalid fields
+}
+
+// OK reports whether every required field was present and well-typed
+func (r *FieldReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Invalid) == 0
+}
+
+func (r *FieldReport) Error() string {
+	parts := make([]string, 0, 2)
+	if len(r.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(r.Missing, ", "))
+	}
+	if len(r.Invalid) > 0 {
+		parts = append(parts, "invalid "+strings.Join(r.Invalid, ", "))
+	}
+	return fmt.Sprintf("%s result: %s", r.Op, strings.Join(parts, "; "))
+}
+
+// merge adds another report's fields, skipping duplicates
+func (r *FieldReport) merge(other *FieldReport) {
+	r.Missing = appendUnique(r.Missing, other.Missing...)
+	r.Invalid = appendUnique(r.Invalid, other.Invalid...)
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// decodeStrict decodes a JSON object into the struct dst points to, field
+// by field. Fields are required unless their json tag has omitempty; a
+// required field that is absent or null is reported missing, any field of
+// the wrong type is reported invalid and left at its zero value.
+func decodeStrict(op string, raw json.RawMessage, dst interface{}) *FieldReport {
+	report := &FieldReport{Op: op}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		report.Invalid = append(report.Invalid, "(result)")
+		return report
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, opts, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		optional := strings.Contains(opts, "omitempty")
+
+		value, ok := obj[name]
+		if !ok || string(value) == "null" {
+			if !optional {
+				report.Missing = append(report.Missing, name)
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(value, v.Field(i).Addr().Interface()); err != nil {
+			report.Invalid = append(report.Invalid, name)
+		}
+	}
+
+	return report
+}
+
+// decodeList decodes a JSON array of objects with decodeStrict, dropping
+// elements with missing or invalid required fields. The merged report of
+// all elements is returned alongside, with the number dropped.
+func decodeList[T any](op string, raw json.RawMessage) ([]T, *FieldReport) {
+	report := &FieldReport{Op: op}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		report.Invalid = append(report.Invalid, "(result)")
+		return nil, report
+	}
+
+	result := make([]T, 0, len(elems))
+	for _, elem := range elems {
+		var item T
+		r := decodeStrict(op, elem, &item)
+		report.merge(r)
+		if r.OK() {
+			result = append(result, item)
+		} else {
+			report.Dropped++
+		}
+	}
+
+	return result, report
+}
+
+// adapterHealth keeps the latest field report per script, so a game update
+// that drops fields is noticed instead of producing a hero at 0,0 with 0 HP
+type adapterHealth struct {
+	mu      sync.Mutex
+	reports map[string]*FieldReport
+	dropped map[string]string // last dropped elements warning per list script
+}
+
+// record stores a report and logs when a script breaks or recovers
+func (c *Client) record(report *FieldReport) {
+	h := &c.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.reports == nil {
+		h.reports = make(map[string]*FieldReport)
+	}
+	prev, seen := h.reports[report.Op]
+	h.reports[report.Op] = report
+
+	adapter := "none"
+	if c.adapter != nil {
+		adapter = c.adapter.Name()
+	}
+	switch {
+	case !report.OK() && (!seen || prev.OK()):
+		c.log.WithField("interface", adapter).WithError(report).Error("Game interface adapter is broken")
+	case report.OK() && seen && !prev.OK():
+		c.log.WithField("interface", adapter).WithField("script", report.Op).Info("Game interface adapter recovered")
+	}
+}
+
+// recordList records a decodeList report. Only a result that isn't a list
+// marks the adapter broken: elements dropped for missing or invalid fields
+// are logged, as one odd mob or item shouldn't hold the bot. The warning is
+// repeated at debug level while the same elements keep being dropped.
+func (c *Client) recordList(report *FieldReport) {
+	if report.Dropped == 0 {
+		c.record(report)
+	} else {
+		c.record(&FieldReport{Op: report.Op})
+	}
+
+	h := &c.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if report.Dropped == 0 {
+		delete(h.dropped, report.Op)
+		return
+	}
+	if h.dropped == nil {
+		h.dropped = make(map[string]string)
+	}
+	entry := c.log.WithError(report).WithField("dropped", report.Dropped)
+	if h.dropped[report.Op] == report.Error() {
+		entry.Debug("Dropped unreadable list elements")
+		return
+	}
+	h.dropped[report.Op] = report.Error()
+	entry.Warn("Dropped unreadable list elements")
+}
+
+// HealthCheck returns an error describing every script whose last result
+// lacked required fields, nil when the adapter works
+func (c *Client) HealthCheck() error {
+	h := &c.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	problems := make([]string, 0)
+	for _, r := range h.reports {
+		if !r.OK() {
+			problems = append(problems, r.Error())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("game interface adapter broken: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testClient returns a client without a browser that logs nowhere
+func testClient() *Client {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewClient(nil, nil, log)
+}
+
+func TestOddListElementsAreDropped(t *testing.T) {
+	c := testClient()
+
+	raw := json.RawMessage(`[
+		{"id": "1", "name": "Wolf", "x": 10, "y": 12, "alive": true, "attackable": true},
+		{"id": "2", "x": "far", "alive": true, "attackable": true}
+	]`)
+	mobs := c.decodeMobs(raw)
+
+	if len(mobs) != 1 || mobs[0].Name != "Wolf" {
+		t.Errorf("mobs = %+v, want only the wolf", mobs)
+	}
+	if err := c.HealthCheck(); err != nil {
+		t.Errorf("health check after a dropped mob: %v", err)
+	}
+}
+
+func TestUnreadableListBreaksAdapter(t *testing.T) {
+	c := testClient()
+
+	if mobs := c.decodeMobs(json.RawMessage(`{"mobs": []}`)); len(mobs) != 0 {
+		t.Errorf("mobs = %+v, want none", mobs)
+	}
+	if err := c.HealthCheck(); err == nil {
+		t.Error("health check passed with mobs not returned as a list")
+	}
+
+	c.decodeMobs(json.RawMessage(`[]`))
+	if err := c.HealthCheck(); err != nil {
+		t.Errorf("health check after the mobs recovered: %v", err)
+	}
+}
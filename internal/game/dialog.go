@@ -0,0 +1,167 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Dialog is an open NPC conversation
+type Dialog struct {
+	NPCID   string
+	NPCName string
+	Text    string
+	Options []DialogOption
+}
+
+// DialogOption is a numbered reply in a dialog, Index starting at 1
+type DialogOption struct {
+	Index   int
+	ReplyID string
+	Text    string
+}
+
+// dialogTimeout is how long to wait for a dialog to show up
+const dialogTimeout = 5 * time.Second
+
+// TalkTo starts a conversation with an NPC
+func (c *Client) TalkTo(npcID string) error {
+	c.log.WithField("npcId", npcID).Debug("Talking to NPC...")
+
+	var success bool
+	if err := c.call(opTalk, &success, npcID); err != nil {
+		return fmt.Errorf("failed to talk to NPC: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("could not talk to NPC %s", npcID)
+	}
+
+	return nil
+}
+
+// GetDialog retrieves the open dialog, nil if no dialog is open
+func (c *Client) GetDialog() (*Dialog, error) {
+	var result *struct {
+		NPCID   string `json:"npcId"`
+		NPCName string `json:"npcName"`
+		Text    string `json:"text"`
+		Options []struct {
+			ReplyID string `json:"replyId"`
+			Text    string `json:"text"`
+		} `json:"options"`
+	}
+	if err := c.call(opDialog, &result); err != nil {
+		return nil, fmt.Errorf("failed to get dialog: %w", err)
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	dialog := &Dialog{
+		NPCID:   result.NPCID,
+		NPCName: result.NPCName,
+		Text:    result.Text,
+		Options: make([]DialogOption, 0, len(result.Options)),
+	}
+	for i, o := range result.Options {
+		dialog.Options = append(dialog.Options, DialogOption{
+			Index:   i + 1,
+			ReplyID: o.ReplyID,
+			Text:    o.Text,
+		})
+	}
+
+	return dialog, nil
+}
+
+// ChooseDialogOption picks a dialog reply by its 1-based index
+func (c *Client) ChooseDialogOption(index int) error {
+	dialog, err := c.waitForDialog()
+	if err != nil {
+		return err
+	}
+	if index < 1 || index > len(dialog.Options) {
+		return fmt.Errorf("dialog option %d out of range (1-%d)", index, len(dialog.Options))
+	}
+
+	return c.chooseOption(dialog, dialog.Options[index-1])
+}
+
+// ChooseDialogOptionText picks the first dialog reply containing match (case-insensitive)
+func (c *Client) ChooseDialogOptionText(match string) error {
+	dialog, err := c.waitForDialog()
+	if err != nil {
+		return err
+	}
+
+	needle := strings.ToLower(match)
+	for _, o := range dialog.Options {
+		if strings.Contains(strings.ToLower(o.Text), needle) {
+			return c.chooseOption(dialog, o)
+		}
+	}
+
+	return fmt.Errorf("no dialog option matching %q", match)
+}
+
+// RunDialog talks to an NPC and answers with each step in turn. A step is
+// either a 1-based option number or text to match against the options.
+func (c *Client) RunDialog(npcID string, steps []string) error {
+	if err := c.TalkTo(npcID); err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		time.Sleep(500 * time.Millisecond)
+
+		var err error
+		if index, convErr := strconv.Atoi(step); convErr == nil {
+			err = c.ChooseDialogOption(index)
+		} else {
+			err = c.ChooseDialogOptionText(step)
+		}
+		if err != nil {
+			return fmt.Errorf("dialog step %d (%q) failed: %w", i+1, step, err)
+		}
+	}
+
+	return nil
+}
+
+// chooseOption sends the reply through the game API or clicks it
+func (c *Client) chooseOption(dialog *Dialog, option DialogOption) error {
+	c.log.WithFields(logrus.Fields{
+		"npc":    dialog.NPCName,
+		"option": option.Text,
+	}).Debug("Choosing dialog option...")
+
+	var success bool
+	if err := c.call(opChooseOption, &success, dialog.NPCID, option.ReplyID, option.Index-1); err != nil {
+		return fmt.Errorf("failed to choose dialog option: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("could not choose dialog option %d", option.Index)
+	}
+
+	return nil
+}
+
+// waitForDialog polls until a dialog with options is open
+func (c *Client) waitForDialog() (*Dialog, error) {
+	start := time.Now()
+	for time.Since(start) < dialogTimeout {
+		dialog, err := c.GetDialog()
+		if err != nil {
+			return nil, err
+		}
+		if dialog != nil && len(dialog.Options) > 0 {
+			return dialog, nil
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	return nil, fmt.Errorf("no dialog open after %v", dialogTimeout)
+}
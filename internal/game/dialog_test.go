@@ -0,0 +1,137 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// guardPage mocks the newer interface with a gate guard who asks for a toll
+// before letting the hero through. Replies move the conversation along the
+// way the game does: the dialogue object changes after each talk request.
+const guardPage = `
+window.calls = [];
+window.Engine = {hero: {d: {x: 10, y: 12}}, npcs: {check: function () { return {}; }}};
+let steps = {
+	"talk&id=5": {id: 5, name: "Guard", text: "Halt!", replies: [{id: 11, text: "Let me pass"}, {id: 12, text: "Bye"}]},
+	"talk&id=5&c=11": {id: 5, name: "Guard", text: "Pay the toll.", replies: [{id: 21, text: "Here you go"}]},
+	"talk&id=5&c=21": null
+};
+window._g = function (q) {
+	window.calls.push(q);
+	Engine.dialogue = {d: steps[q]};
+};
+`
+
+// dialogResult mirrors the dialog script result
+type dialogResult struct {
+	NPCID   string `json:"npcId"`
+	NPCName string `json:"npcName"`
+	Text    string `json:"text"`
+	Options []struct {
+		ReplyID string `json:"replyId"`
+		Text    string `json:"text"`
+	} `json:"options"`
+}
+
+func TestDialogOnEnginePage(t *testing.T) {
+	results, requests := runOnPage(t, NewEngineInterfaceAdapter(), guardPage,
+		scriptCall{opDialog, nil},
+		scriptCall{opTalk, []interface{}{"5"}},
+		scriptCall{opDialog, nil},
+		scriptCall{opChooseOption, []interface{}{"5", "11", 0}},
+		scriptCall{opDialog, nil},
+		scriptCall{opChooseOption, []interface{}{"5", "21", 0}},
+		scriptCall{opDialog, nil})
+
+	if string(results[0]) != "null" {
+		t.Errorf("dialog before talking = %s, want null", results[0])
+	}
+	if string(results[1]) != "true" {
+		t.Errorf("talk = %s, want true", results[1])
+	}
+
+	var first, second dialogResult
+	if err := json.Unmarshal(results[2], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.NPCID != "5" || first.Text != "Halt!" || len(first.Options) != 2 || first.Options[0].ReplyID != "11" {
+		t.Errorf("first dialog = %+v", first)
+	}
+
+	if string(results[3]) != "true" {
+		t.Errorf("first reply = %s, want true", results[3])
+	}
+	if err := json.Unmarshal(results[4], &second); err != nil {
+		t.Fatal(err)
+	}
+	if second.Text != "Pay the toll." || len(second.Options) != 1 || second.Options[0].ReplyID != "21" {
+		t.Errorf("second dialog = %+v", second)
+	}
+
+	if string(results[5]) != "true" {
+		t.Errorf("second reply = %s, want true", results[5])
+	}
+	if string(results[6]) != "null" {
+		t.Errorf("dialog after the last reply = %s, want null", results[6])
+	}
+
+	want := []string{"talk&id=5", "talk&id=5&c=11", "talk&id=5&c=21"}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+// windowDialogPage mocks a dialog only shown as a window, with no game
+// request function to answer through
+const windowDialogPage = `
+window.calls = [];
+window.Engine = {};
+function element(text) {
+	return {
+		innerText: text,
+		click: function () { window.calls.push("clicked " + text); },
+		getAttribute: function () { return null; }
+	};
+}
+let box = element("");
+box.getAttribute = function (name) { return name === "data-npc" ? "5" : null; };
+box.querySelector = function (sel) {
+	return {".dialog-text": element(" Halt! "), ".dialog-title": element("Guard")}[sel] || null;
+};
+box.querySelectorAll = function (sel) {
+	return sel === ".dialog-options li" ? [element("Let me pass"), element("Bye")] : [];
+};
+document.querySelector = function (sel) { return sel === ".dialog-window" ? box : null; };
+document.querySelectorAll = function (sel) {
+	return sel.indexOf(".dialog-options li") >= 0 ? box.querySelectorAll(".dialog-options li") : [];
+};
+`
+
+func TestDialogWindowOnEnginePage(t *testing.T) {
+	results, requests := runOnPage(t, NewEngineInterfaceAdapter(), windowDialogPage,
+		scriptCall{opDialog, nil},
+		scriptCall{opChooseOption, []interface{}{"5", "", 1}})
+
+	var dialog dialogResult
+	if err := json.Unmarshal(results[0], &dialog); err != nil {
+		t.Fatal(err)
+	}
+	if dialog.NPCID != "5" || dialog.NPCName != "Guard" || dialog.Text != "Halt!" {
+		t.Errorf("dialog = %+v", dialog)
+	}
+	if len(dialog.Options) != 2 || dialog.Options[1].Text != "Bye" || dialog.Options[1].ReplyID != "" {
+		t.Errorf("options = %+v", dialog.Options)
+	}
+
+	if string(results[1]) != "true" {
+		t.Errorf("choose_option = %s, want true", results[1])
+	}
+	if len(requests) != 1 || requests[0] != "clicked Bye" {
+		t.Errorf("clicks = %v, want the second option", requests)
+	}
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// MobExp is the exp earned from one kind of mob
+type MobExp struct {
+	Kills int
+	Exp   int
+}
+
+// PerKill returns the average exp per kill
+func (m MobExp) PerKill() int {
+	if m.Kills == 0 {
+		return 0
+	}
+	return m.Exp / m.Kills
+}
+
+// ExpReport summarizes the session's exp progress
+type ExpReport struct {
+	Level   int
+	Gained  int
+	PerHour int
+	ToLevel int           // exp missing to the next level
+	ETA     time.Duration // at the session's rate, 0 when unknown
+}
+
+// ExpForLevel approximates the total exp needed to reach a level, for game
+// versions that don't expose it
+func ExpForLevel(level int) int {
+	return int(math.Pow(float64(level), 4)) + 10
+}
+
+// lateExpWindow is how long after a won battle exp still counts for its
+// kills; the game often updates the hero's exp a few polls after the
+// battle window closes
+const lateExpWindow = 5 * time.Second
+
+// trackExp adds the exp gained between two hero reads to the session and
+// to the exp waiting for the next kill, or to the last battle's kills when
+// it arrives shortly after them. Levelling up may reset the counter; the
+// exp that was missing to the level then counts as gained. Exp is optional
+// in the hero script, so a read without it and the reload on a map change
+// are skipped rather than counted as the whole exp total.
+func (sm *StateManager) trackExp(prev, hero *HeroState) {
+	if prev.Level == 0 || hero.Level == 0 {
+		return
+	}
+	if !prev.ExpKnown || !hero.ExpKnown || prev.MapID != hero.MapID {
+		return
+	}
+
+	gained := hero.Exp - prev.Exp
+	if hero.Level > prev.Level && gained < 0 {
+		gained = prev.expNext() - prev.Exp + hero.Exp
+	}
+	if gained <= 0 {
+		return
+	}
+
+	sm.stats.Exp += gained
+	if len(sm.lastKills) > 0 && time.Since(sm.lastKillsAt) < lateExpWindow {
+		sm.creditKills(sm.lastKills, gained)
+		return
+	}
+	sm.pendingExp += gained
+}
+
+// expNext returns the exp of the next level, estimated when unknown
+func (h *HeroState) expNext() int {
+	if h.ExpNext > 0 {
+		return h.ExpNext
+	}
+	return ExpForLevel(h.Level + 1)
+}
+
+// ExpReport returns the session's exp progress and the time to level up
+func (sm *StateManager) ExpReport() ExpReport {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	report := ExpReport{
+		Level:  sm.hero.Level,
+		Gained: sm.stats.Exp,
+	}
+	if sm.hero.Level == 0 {
+		return report
+	}
+
+	report.ToLevel = sm.hero.expNext() - sm.hero.Exp
+	if report.ToLevel < 0 {
+		report.ToLevel = 0
+	}
+
+	hours := time.Since(sm.stats.Started).Hours()
+	if hours > 0 && sm.stats.Exp > 0 {
+		rate := float64(sm.stats.Exp) / hours
+		report.PerHour = int(rate)
+		report.ETA = time.Duration(float64(report.ToLevel) / rate * float64(time.Hour))
+	}
+
+	return report
+}
@@ -0,0 +1,46 @@
+package game
+
+import "testing"
+
+func heroWithExp(exp int) *HeroState {
+	return &HeroState{MapID: "1", Level: 5, Exp: exp, ExpKnown: true}
+}
+
+func TestLateExpGoesToLastKills(t *testing.T) {
+	sm := NewStateManager()
+	sm.UpdateHero(heroWithExp(0)) // a fresh character starts at 0 exp
+
+	sm.BattleStarted()
+	sm.UpdateHero(heroWithExp(10))
+	sm.RecordKills([]string{"Wolf", "Rat"})
+
+	// The game updates exp a poll after the battle window closed
+	sm.UpdateHero(heroWithExp(30))
+
+	stats := sm.GetStats()
+	if stats.Exp != 30 {
+		t.Errorf("session exp = %d, want 30", stats.Exp)
+	}
+	if wolf, rat := stats.MobExp["Wolf"], stats.MobExp["Rat"]; wolf.Exp != 15 || rat.Exp != 15 || wolf.Kills != 1 || rat.Kills != 1 {
+		t.Errorf("mob exp = %+v, want 15 exp and one kill each", stats.MobExp)
+	}
+
+	// The next battle starts clean
+	sm.BattleStarted()
+	sm.UpdateHero(heroWithExp(40))
+	sm.RecordKills([]string{"Wolf"})
+	if wolf := sm.GetStats().MobExp["Wolf"]; wolf.Exp != 25 || wolf.Kills != 2 {
+		t.Errorf("wolf = %+v, want 25 exp over 2 kills", wolf)
+	}
+}
+
+func TestExpReadsWithoutExpAreSkipped(t *testing.T) {
+	sm := NewStateManager()
+	sm.UpdateHero(heroWithExp(100))
+	sm.UpdateHero(&HeroState{MapID: "1", Level: 5}) // exp not reported
+	sm.UpdateHero(heroWithExp(120))
+
+	if exp := sm.GetStats().Exp; exp != 0 {
+		t.Errorf("session exp = %d, want 0 with no baseline before the last read", exp)
+	}
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Item represents an item in a loot window, on the ground or in the bags
+type Item struct {
+	ID       string
+	Name     string
+	Type     string // item class, e.g. "weapon", "potion", "neutral"
+	Rarity   string // "common", "unique", "heroic", "legendary"
+	Value    int    // merchant price in gold
+	Quantity int
+	Source   string // "window", "ground" or "bag"
+}
+
+// Inventory is a snapshot of the hero's bags
+type Inventory struct {
+	Items    []*Item
+	Capacity int // total bag slots, 0 if unknown
+}
+
+// FreeSlots returns the number of empty bag slots, -1 if capacity is unknown
+func (inv *Inventory) FreeSlots() int {
+	if inv.Capacity == 0 {
+		return -1
+	}
+	return inv.Capacity - len(inv.Items)
+}
+
+// GetLoot retrieves items offered in the loot window and lying on the ground near the hero
+func (c *Client) GetLoot() ([]*Item, error) {
+	result, err := c.readItems("window", "ground")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loot: %w", err)
+	}
+
+	return result.Items, nil
+}
+
+// ResolveLoot answers the loot window, taking the given item IDs and declining the rest
+func (c *Client) ResolveLoot(take, decline []string) error {
+	c.log.WithField("take", len(take)).Debug("Resolving loot...")
+
+	var success bool
+	if err := c.call(opLoot, &success, strings.Join(take, ","), strings.Join(decline, ",")); err != nil {
+		return fmt.Errorf("failed to resolve loot: %w", err)
+	}
+
+	if !success {
+		return fmt.Errorf("could not answer loot window")
+	}
+
+	return nil
+}
+
+// PickUpItem picks up an item lying on the ground under the hero
+func (c *Client) PickUpItem(itemID string) error {
+	c.log.WithField("itemId", itemID).Debug("Picking up item...")
+
+	var success bool
+	if err := c.call(opRequest, &success, "takeitem&id="+itemID); err != nil {
+		return fmt.Errorf("failed to pick up item: %w", err)
+	}
+
+	if !success {
+		return fmt.Errorf("could not pick up item")
+	}
+
+	return nil
+}
+
+// GetInventory retrieves the items in the hero's bags
+func (c *Client) GetInventory() (*Inventory, error) {
+	result, err := c.readItems("bag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	return &Inventory{
+		Items:    result.Items,
+		Capacity: result.Capacity,
+	}, nil
+}
+
+// itemResult is one entry of the items script result
+type itemResult struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Rarity   string `json:"rarity,omitempty"`
+	Value    int    `json:"value,omitempty"`
+	Quantity int    `json:"quantity,omitempty"`
+	Source   string `json:"source"`
+}
+
+// itemsResult holds the decoded items and bag capacity
+type itemsResult struct {
+	Items    []*Item
+	Capacity int
+}
+
+// readItems reads the items from the given places ("window", "ground", "bag", "shop")
+func (c *Client) readItems(sources ...string) (*itemsResult, error) {
+	var raw json.RawMessage
+	if err := c.call(opItems, &raw, sources); err != nil {
+		return nil, err
+	}
+	if string(raw) == "null" {
+		return nil, fmt.Errorf("items not readable")
+	}
+
+	var result struct {
+		Items    json.RawMessage `json:"items"`
+		Capacity int             `json:"capacity"`
+	}
+	if report := decodeStrict(opItems, raw, &result); !report.OK() {
+		c.record(report)
+		return nil, report
+	}
+
+	decoded, report := decodeList[itemResult](opItems, result.Items)
+	c.recordList(report)
+
+	items := make([]*Item, 0, len(decoded))
+	for _, it := range decoded {
+		items = append(items, &Item{
+			ID:       it.ID,
+			Name:     it.Name,
+			Type:     it.Type,
+			Rarity:   it.Rarity,
+			Value:    it.Value,
+			Quantity: it.Quantity,
+			Source:   it.Source,
+		})
+	}
+
+	return &itemsResult{Items: items, Capacity: result.Capacity}, nil
+}
@@ -0,0 +1,19 @@
+// Returns the strategy used, or the mob position for the click fallback
+function (mobId) {
+	try {
+		let npcList = window.npcs || window.NPC || (window.g && window.g.npcs) || {};
+		let target = npcList[mobId];
+
+		if (!target) return {found: false};
+
+		if (window.hero && window.hero.attack) {
+			window.hero.attack(target);
+			return {found: true, strategy: "hero.attack"};
+		}
+
+		return {found: true, x: target.x || target.posX || 0, y: target.y || target.posY || 0};
+	} catch (e) {
+		console.error("Error attacking:", e);
+		return {found: false};
+	}
+}
@@ -0,0 +1,8 @@
+function () {
+	try {
+		let hero = window.hero || window.Hero || (window.g && window.g.hero) || {};
+		return !!((window.g && window.g.battle) || hero.inCombat || hero.incombat);
+	} catch (e) {
+		return false;
+	}
+}
@@ -0,0 +1,33 @@
+// Reads the running battle: whose turn it is, auto-fight and living enemies
+function () {
+	try {
+		let b = window.g && window.g.battle;
+		if (!b) return {active: false};
+
+		let hero = window.hero || {};
+		let warriors = b.warriors || b.f || {};
+		let myTeam = warriors[hero.id] ? warriors[hero.id].team : 1;
+		let enemies = [];
+
+		for (let id in warriors) {
+			let w = warriors[id];
+			if (!w || w.team === myTeam || w.hpp <= 0) continue;
+
+			enemies.push({
+				id: String(id),
+				name: w.name,
+				hpPercent: w.hpp
+			});
+		}
+
+		return {
+			active: true,
+			myTurn: !!(b.move || b.myturn),
+			auto: !!(b.auto || b.autobattle),
+			enemies: enemies
+		};
+	} catch (e) {
+		console.error("Error reading battle:", e);
+		return null;
+	}
+}
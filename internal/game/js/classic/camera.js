@@ -0,0 +1,26 @@
+// The classic interface scrolls the #ground layer under a fixed viewport
+function () {
+	try {
+		let ground = document.querySelector('#ground');
+		let canvas = document.querySelector('#centerbox') || ground;
+		if (!canvas) return null;
+		let box = canvas.getBoundingClientRect();
+		let origin = ground ? ground.getBoundingClientRect() : box;
+		let map = window.map || (window.g && window.g.map) || {};
+
+		return {
+			left: box.left,
+			top: box.top,
+			width: box.width,
+			height: box.height,
+			originX: origin.left,
+			originY: origin.top,
+			tileSize: map.tileSize || 32,
+			mapWidth: map.x || 0,
+			mapHeight: map.y || 0
+		};
+	} catch (e) {
+		console.error("Error reading camera:", e);
+		return null;
+	}
+}
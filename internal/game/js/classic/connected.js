@@ -0,0 +1,18 @@
+function () {
+	try {
+		// Check if socket/connection is alive
+		if (window.connection && window.connection.connected !== undefined) {
+			return window.connection.connected;
+		}
+		if (window.socket && window.socket.connected !== undefined) {
+			return window.socket.connected;
+		}
+		if (window.ws && window.ws.readyState !== undefined) {
+			return window.ws.readyState === 1; // WebSocket.OPEN
+		}
+		// Assume connected if hero exists
+		return window.hero !== undefined && window.hero !== null;
+	} catch (e) {
+		return false;
+	}
+}
@@ -0,0 +1,4 @@
+// Classic interface: the hero, map and NPCs are globals
+function () {
+	return !!(window.hero || window.Hero || (window.g && window.g.hero));
+}
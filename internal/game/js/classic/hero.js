@@ -0,0 +1,42 @@
+// Required fields are left undefined, not defaulted, when the game lacks them
+function () {
+	try {
+		let hero = window.hero || window.Hero || (window.g && window.g.hero);
+		if (!hero) return null;
+		let mapId = (hero.map && hero.map.id) ?? hero.mapId ?? (window.map && window.map.id);
+		let buffs = hero.buffs || (window.g && window.g.buffs) || [];
+		let effects = [];
+		for (let id in buffs) {
+			let b = buffs[id];
+			if (!b) continue;
+			effects.push({
+				name: b.name,
+				remaining: b.time || b.left || 0,
+				stacks: b.stacks || b.amount || 1,
+				debuff: !!(b.debuff || b.negative)
+			});
+		}
+
+		return {
+			x: hero.x ?? hero.posX,
+			y: hero.y ?? hero.posY,
+			mapId: mapId === undefined ? undefined : String(mapId),
+			nick: hero.nick,
+			hp: hero.hp ?? hero.HP,
+			hpMax: hero.maxhp ?? hero.maxHP ?? hero.hpMax,
+			mp: hero.mp || hero.MP || 0,
+			mpMax: hero.maxmp || hero.maxMP || hero.mpMax || 0, // 0 = no mana
+			level: hero.lvl ?? hero.level,
+			profession: hero.prof,
+			effects: effects,
+			exp: hero.exp,
+			expNext: hero.nextexp ?? hero.expNext,
+			gold: hero.gold || 0,
+			inCombat: hero.inCombat || hero.incombat || false,
+			dead: hero.dead || hero.isDead || hero.hp <= 0
+		};
+	} catch (e) {
+		console.error("Error getting hero state:", e);
+		return null;
+	}
+}
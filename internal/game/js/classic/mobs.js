@@ -0,0 +1,29 @@
+function () {
+	try {
+		let npcList = window.npcs || window.NPC || (window.g && window.g.npcs) || [];
+		let mobs = [];
+
+		for (let id in npcList) {
+			let npc = npcList[id];
+			if (!npc || npc.type !== 1) continue; // type 1 = monster
+
+			mobs.push({
+				id: String(id),
+				name: npc.nick ?? npc.name,
+				level: npc.lvl || npc.level || 1,
+				x: npc.x ?? npc.posX,
+				y: npc.y ?? npc.posY,
+				hp: npc.hp || 0,
+				hpMax: npc.maxhp || npc.hpMax || 100,
+				alive: !npc.dead && npc.hp > 0,
+				attackable: !npc.dead && npc.hp > 0,
+				aggressive: !!(npc.agressive || npc.aggressive)
+			});
+		}
+
+		return mobs;
+	} catch (e) {
+		console.error("Error getting mobs:", e);
+		return [];
+	}
+}
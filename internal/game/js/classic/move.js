@@ -0,0 +1,12 @@
+function (x, y) {
+	try {
+		if (window.hero && window.hero.moveTo) {
+			window.hero.moveTo(x, y);
+			return true;
+		}
+		return false;
+	} catch (e) {
+		console.error("Error moving:", e);
+		return false;
+	}
+}
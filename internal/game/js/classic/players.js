@@ -0,0 +1,27 @@
+function () {
+	try {
+		let others = window.others || (window.g && window.g.other) || {};
+		let players = [];
+
+		for (let id in others) {
+			let o = others[id];
+			if (!o) continue;
+
+			players.push({
+				id: String(id),
+				nick: o.nick ?? o.name,
+				level: o.lvl || o.level || 0,
+				clan: (o.clan && (o.clan.name || o.clan)) || "",
+				x: o.x ?? o.posX,
+				y: o.y ?? o.posY,
+				inBattle: !!(o.battle || o.inBattle),
+				targetId: String(o.target || o.attackTarget || "")
+			});
+		}
+
+		return players;
+	} catch (e) {
+		console.error("Error getting players:", e);
+		return [];
+	}
+}
@@ -0,0 +1,21 @@
+// Clicks the respawn button, or respawns through the hero object
+function () {
+	try {
+		let btn = document.querySelector('#respawn');
+		if (btn) {
+			btn.click();
+			return true;
+		}
+
+		// Try game API
+		if (window.hero && window.hero.respawn) {
+			window.hero.respawn();
+			return true;
+		}
+
+		return false;
+	} catch (e) {
+		console.error("Error respawning:", e);
+		return false;
+	}
+}
@@ -0,0 +1,5 @@
+// An open merchant sets window.g.shop, the #shop window shows it too
+function () {
+	return !!((window.g && window.g.shop && window.g.shop.id) ||
+		document.querySelector('#shop:not([style*="none"])'));
+}
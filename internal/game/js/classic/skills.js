@@ -0,0 +1,24 @@
+// Lists the hero's battle skills with turns of cooldown left and mana cost
+function () {
+	try {
+		let list = (window.g && window.g.skills) || window.skills || {};
+		let skills = [];
+
+		for (let id in list) {
+			let s = list[id];
+			if (!s) continue;
+
+			skills.push({
+				id: String(s.id ?? id),
+				name: s.name,
+				cooldown: s.cd || s.cooldown || 0,
+				manaCost: s.mp || s.mana || 0
+			});
+		}
+
+		return skills;
+	} catch (e) {
+		console.error("Error getting skills:", e);
+		return null;
+	}
+}
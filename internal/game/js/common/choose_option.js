@@ -0,0 +1,18 @@
+// Picks a dialog reply by ID through the game API, or clicks it by 0-based index
+function (npcId, replyId, index) {
+	try {
+		if (replyId && window._g) {
+			window._g("talk&id=" + npcId + "&c=" + replyId);
+			return true;
+		}
+		let options = document.querySelectorAll('#dialog .answer, .dialog-window .dialog-options li');
+		if (options[index]) {
+			options[index].click();
+			return true;
+		}
+		return false;
+	} catch (e) {
+		console.error("Error choosing dialog option:", e);
+		return false;
+	}
+}
@@ -0,0 +1,4 @@
+// A loaded hero means a saved session got us past the login page
+function () {
+	return !!(window.hero || (window.Engine && window.Engine.hero));
+}
@@ -0,0 +1,21 @@
+// Fills in and submits the login form
+function (username, password) {
+	try {
+		let userField = document.querySelector('input[name="login"], input[name="username"], #login, #username');
+		let passField = document.querySelector('input[name="password"], input[type="password"], #password');
+		let loginBtn = document.querySelector('button[type="submit"], input[type="submit"], .login-button, #login-button');
+
+		if (userField && passField) {
+			userField.value = username;
+			passField.value = password;
+			if (loginBtn) {
+				loginBtn.click();
+				return true;
+			}
+		}
+		return false;
+	} catch (e) {
+		console.error('Login error:', e);
+		return false;
+	}
+}
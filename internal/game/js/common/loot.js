@@ -0,0 +1,18 @@
+// Answers the loot window; take and decline are comma separated item IDs
+function (take, decline) {
+	try {
+		if (window._g) {
+			window._g("loot&not=" + decline + "&want=&must=" + take + "&final=1");
+			return true;
+		}
+		let btn = document.querySelector('#loots .accept, .loot-window .accept-button');
+		if (btn) {
+			btn.click();
+			return true;
+		}
+		return false;
+	} catch (e) {
+		console.error("Error resolving loot:", e);
+		return false;
+	}
+}
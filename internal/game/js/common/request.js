@@ -0,0 +1,11 @@
+// Sends a raw game request (shop, item pickup...)
+function (query) {
+	try {
+		if (!window._g) return false;
+		window._g(query);
+		return true;
+	} catch (e) {
+		console.error("Error in game request:", e);
+		return false;
+	}
+}
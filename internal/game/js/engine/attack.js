@@ -0,0 +1,24 @@
+// Returns the strategy used, or the mob position for the click fallback
+function (mobId) {
+	try {
+		let npcList = (Engine.npcs && Engine.npcs.check && Engine.npcs.check()) || {};
+		let target = npcList[mobId];
+
+		if (!target) return {found: false};
+
+		if (Engine.attack) {
+			Engine.attack(target);
+			return {found: true, strategy: "Engine.attack"};
+		}
+		if (window._g) {
+			window._g("fight&a=attack&ff=1&id=-" + mobId);
+			return {found: true, strategy: "_g fight"};
+		}
+
+		let d = target.d || target;
+		return {found: true, x: d.x || 0, y: d.y || 0};
+	} catch (e) {
+		console.error("Error attacking:", e);
+		return {found: false};
+	}
+}
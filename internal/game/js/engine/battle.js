@@ -0,0 +1,7 @@
+function () {
+	try {
+		return !!(Engine.battle && (Engine.battle.show || Engine.battle.isActive));
+	} catch (e) {
+		return false;
+	}
+}
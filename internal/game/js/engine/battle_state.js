@@ -0,0 +1,33 @@
+// Reads the running battle: whose turn it is, auto-fight and living enemies
+function () {
+	try {
+		let b = Engine.battle;
+		if (!b || !(b.show || b.isActive)) return {active: false};
+
+		let heroId = Engine.hero && Engine.hero.d && Engine.hero.d.id;
+		let warriors = b.warriorsList || b.warriors || {};
+		let myTeam = warriors[heroId] ? warriors[heroId].team : 1;
+		let enemies = [];
+
+		for (let id in warriors) {
+			let w = warriors[id];
+			if (!w || w.team === myTeam || w.hpp <= 0) continue;
+
+			enemies.push({
+				id: String(id),
+				name: w.name,
+				hpPercent: w.hpp
+			});
+		}
+
+		return {
+			active: true,
+			myTurn: !!(b.myTurn || (b.isMyTurn && b.isMyTurn())),
+			auto: !!(b.auto || b.autoFight),
+			enemies: enemies
+		};
+	} catch (e) {
+		console.error("Error reading battle:", e);
+		return null;
+	}
+}
@@ -0,0 +1,26 @@
+// The newer interface draws on one canvas and keeps a camera offset in pixels
+function () {
+	try {
+		let canvas = document.querySelector('#GAME_CANVAS') || document.querySelector('canvas');
+		if (!canvas) return null;
+		let box = canvas.getBoundingClientRect();
+		let map = Engine.map || {};
+		let offset = map.offset || [0, 0];
+		let size = map.d || {};
+
+		return {
+			left: box.left,
+			top: box.top,
+			width: box.width,
+			height: box.height,
+			originX: box.left - (offset[0] || offset.x || 0),
+			originY: box.top - (offset[1] || offset.y || 0),
+			tileSize: map.tileSize || 32,
+			mapWidth: size.x || 0,
+			mapHeight: size.y || 0
+		};
+	} catch (e) {
+		console.error("Error reading camera:", e);
+		return null;
+	}
+}
@@ -0,0 +1,39 @@
+// Returns chat messages stamped at or after since (unix milliseconds)
+function (since) {
+	try {
+		let messages = [];
+		let log = Engine.chat && Engine.chat.messages;
+		if (log) {
+			for (let i = 0; i < log.length; i++) {
+				let m = log[i].d || log[i];
+				let ts = m.ts > 1e12 ? m.ts : (m.ts || 0) * 1000; // game time stamps are in seconds
+				if (ts < since) continue;
+				messages.push({
+					ts: ts,
+					channel: String(m.k || m.channel || "local"),
+					author: m.n || m.nick || m.author || "",
+					text: m.t || m.text || ""
+				});
+			}
+			return messages;
+		}
+
+		let lines = document.querySelectorAll('.chat-message');
+		for (let i = 0; i < lines.length; i++) {
+			let ts = parseInt(lines[i].getAttribute("data-ts") || "0", 10);
+			if (ts < since) continue;
+			let author = lines[i].querySelector('.author');
+			let text = lines[i].querySelector('.text');
+			messages.push({
+				ts: ts,
+				channel: lines[i].getAttribute("data-channel") || "local",
+				author: author ? author.innerText.trim() : "",
+				text: text ? text.innerText.trim() : (lines[i].innerText || "").trim()
+			});
+		}
+		return messages;
+	} catch (e) {
+		console.error("Error getting chat:", e);
+		return [];
+	}
+}
@@ -0,0 +1,12 @@
+function () {
+	try {
+		let comm = Engine.communication;
+		if (comm && comm.ws && comm.ws.readyState !== undefined) {
+			return comm.ws.readyState === 1; // WebSocket.OPEN
+		}
+		// Assume connected if the hero is loaded
+		return !!(Engine.hero && Engine.hero.d);
+	} catch (e) {
+		return false;
+	}
+}
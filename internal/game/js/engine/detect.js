@@ -0,0 +1,4 @@
+// Newer interface: everything hangs off window.Engine
+function () {
+	return !!(window.Engine && window.Engine.hero && window.Engine.hero.d);
+}
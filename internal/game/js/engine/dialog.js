@@ -0,0 +1,36 @@
+// Returns the open dialog, null when there is none
+function () {
+	try {
+		let talk = Engine.dialogue && Engine.dialogue.d;
+		if (talk && talk.id) {
+			let replies = talk.replies || [];
+			return {
+				npcId: String(talk.id),
+				npcName: talk.name || "",
+				text: talk.text || "",
+				options: replies.map(function (r) {
+					return {replyId: String(r.id), text: r.text || ""};
+				})
+			};
+		}
+
+		let box = document.querySelector('.dialog-window');
+		if (!box) return null;
+		let text = box.querySelector('.dialog-text');
+		let name = box.querySelector('.dialog-title');
+		let options = box.querySelectorAll('.dialog-options li');
+		let result = [];
+		for (let i = 0; i < options.length; i++) {
+			result.push({replyId: "", text: (options[i].innerText || "").trim()});
+		}
+		return {
+			npcId: box.getAttribute("data-npc") || "",
+			npcName: name ? name.innerText.trim() : "",
+			text: text ? text.innerText.trim() : "",
+			options: result
+		};
+	} catch (e) {
+		console.error("Error getting dialog:", e);
+		return null;
+	}
+}
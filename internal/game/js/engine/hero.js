@@ -0,0 +1,45 @@
+// Required fields are left undefined, not defaulted, when the game lacks them
+function () {
+	try {
+		let hero = Engine.hero && Engine.hero.d;
+		if (!hero) return null;
+		let stats = hero.warrior_stats || {};
+		let hp = stats.hp ?? hero.hp;
+		let mapId = Engine.map && Engine.map.d && Engine.map.d.id;
+		let buffs = hero.buffs || (Engine.buffs && (Engine.buffs.list || Engine.buffs.buffs)) || [];
+		let effects = [];
+		for (let id in buffs) {
+			let b = buffs[id];
+			let d = b && (b.d || b);
+			if (!d) continue;
+			effects.push({
+				name: d.name,
+				remaining: d.time || d.left || 0,
+				stacks: d.stacks || d.amount || 1,
+				debuff: !!(d.debuff || d.negative)
+			});
+		}
+
+		return {
+			x: hero.x,
+			y: hero.y,
+			mapId: mapId === undefined ? undefined : String(mapId),
+			nick: hero.nick,
+			hp: hp,
+			hpMax: stats.maxhp ?? hero.maxhp,
+			mp: stats.mana || hero.mp || 0,
+			mpMax: stats.maxmana || hero.maxmp || 0, // 0 = no mana
+			level: hero.lvl,
+			profession: hero.prof,
+			effects: effects,
+			exp: hero.exp,
+			expNext: hero.nextexp ?? hero.expNext,
+			gold: hero.gold || 0,
+			inCombat: !!(Engine.battle && Engine.battle.show),
+			dead: !!hero.dead || hp <= 0
+		};
+	} catch (e) {
+		console.error("Error getting hero state:", e);
+		return null;
+	}
+}
@@ -0,0 +1,61 @@
+// Reads items from the given places: "window" (loot window), "ground"
+// (under the hero), "bag" and "shop" (the open merchant)
+function (sources) {
+	function itemInfo(id, it, source) {
+		let stat = it.stat || "";
+		let rarity = it.rarity || (/rarity=(\w+)/.exec(stat) || [])[1] || "common";
+		let amount = it.amount || parseInt((/amount=(\d+)/.exec(stat) || [])[1] || "1", 10);
+		return {
+			id: String(id),
+			name: it.name || "",
+			type: String(it.type || it.cl || ""),
+			rarity: rarity,
+			value: it.pr || it.price || 0,
+			quantity: amount,
+			source: source
+		};
+	}
+
+	try {
+		let want = {};
+		sources.forEach(function (s) { want[s] = true; });
+		let items = [];
+
+		if (want.window) {
+			let loots = (Engine.loots && Engine.loots.items) || {};
+			for (let id in loots) {
+				let it = loots[id] && (loots[id].d || loots[id]);
+				if (it) items.push(itemInfo(id, it, "window"));
+			}
+		}
+
+		let hero = Engine.hero && Engine.hero.d;
+		let shop = Engine.shop && Engine.shop.d;
+		let all = (Engine.items && Engine.items.items) || {};
+		for (let id in all) {
+			let it = all[id] && (all[id].d || all[id]);
+			if (!it) continue;
+
+			if (want.ground && it.loc === "m" && hero) { // "m" = lying on the map
+				if (Math.abs(it.x - hero.x) <= 1 && Math.abs(it.y - hero.y) <= 1) {
+					items.push(itemInfo(id, it, "ground"));
+				}
+			} else if (want.bag && it.loc === "g") { // "g" = in the bags
+				items.push(itemInfo(id, it, "bag"));
+			} else if (want.shop && it.loc === "n" && !(shop && shop.id && it.own != shop.id)) { // "n" = NPC shop
+				items.push(itemInfo(id, it, "shop"));
+			}
+		}
+
+		let capacity = 0;
+		let bags = (Engine.bags && Engine.bags.list) || [];
+		for (let i = 0; i < bags.length; i++) {
+			capacity += (bags[i] && bags[i][0]) || 0;
+		}
+
+		return {items: items, capacity: capacity};
+	} catch (e) {
+		console.error("Error getting items:", e);
+		return null;
+	}
+}
@@ -0,0 +1,32 @@
+function () {
+	try {
+		let npcList = (Engine.npcs && Engine.npcs.check && Engine.npcs.check()) || {};
+		let mobs = [];
+
+		for (let id in npcList) {
+			let npc = npcList[id];
+			let d = npc && (npc.d || npc);
+			// type 2 = monster, 3 = monster that attacks on sight
+			if (!d || (d.type !== 2 && d.type !== 3)) continue;
+
+			// Dead mobs are removed from the list, so listed ones are alive
+			mobs.push({
+				id: String(id),
+				name: d.nick,
+				level: d.lvl || 1,
+				x: d.x,
+				y: d.y,
+				hp: d.hp || 100,
+				hpMax: d.maxhp || 100,
+				alive: !d.dead,
+				attackable: !d.dead,
+				aggressive: d.type === 3
+			});
+		}
+
+		return mobs;
+	} catch (e) {
+		console.error("Error getting mobs:", e);
+		return [];
+	}
+}
@@ -0,0 +1,16 @@
+function (x, y) {
+	try {
+		if (Engine.hero && Engine.hero.autoGoTo) {
+			Engine.hero.autoGoTo({x: x, y: y});
+			return true;
+		}
+		if (Engine.moveHero) {
+			Engine.moveHero(x, y);
+			return true;
+		}
+		return false;
+	} catch (e) {
+		console.error("Error moving:", e);
+		return false;
+	}
+}
@@ -0,0 +1,28 @@
+function () {
+	try {
+		let others = (Engine.others && Engine.others.check && Engine.others.check()) || {};
+		let players = [];
+
+		for (let id in others) {
+			let o = others[id];
+			let d = o && (o.d || o);
+			if (!d) continue;
+
+			players.push({
+				id: String(id),
+				nick: d.nick,
+				level: d.lvl || 0,
+				clan: (d.clan && (d.clan.name || d.clan)) || "",
+				x: d.x,
+				y: d.y,
+				inBattle: !!(d.battle || d.inBattle),
+				targetId: String(d.target || "")
+			});
+		}
+
+		return players;
+	} catch (e) {
+		console.error("Error getting players:", e);
+		return [];
+	}
+}
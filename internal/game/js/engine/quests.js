@@ -0,0 +1,37 @@
+// Reads the quest log from Engine.quests, or the quest window
+function () {
+	try {
+		let quests = [];
+		let log = Engine.quests && (Engine.quests.list || Engine.quests.quests);
+		if (log) {
+			for (let id in log) {
+				let q = log[id] && (log[id].d || log[id]);
+				if (!q) continue;
+				let objectives = q.objectives || q.tasks || [q.text || q.desc || ""];
+				quests.push({
+					id: String(id),
+					name: q.name || q.title || "",
+					objectives: objectives.map(function (o) { return typeof o === "string" ? o : (o.text || ""); })
+				});
+			}
+			return quests;
+		}
+
+		let entries = document.querySelectorAll('.quest-log .quest-entry');
+		for (let i = 0; i < entries.length; i++) {
+			let title = entries[i].querySelector('.title');
+			let steps = entries[i].querySelectorAll('.objective');
+			let objectives = [];
+			for (let j = 0; j < steps.length; j++) objectives.push((steps[j].innerText || "").trim());
+			quests.push({
+				id: entries[i].getAttribute("data-id") || String(i),
+				name: title ? title.innerText.trim() : "",
+				objectives: objectives
+			});
+		}
+		return quests;
+	} catch (e) {
+		console.error("Error getting quests:", e);
+		return [];
+	}
+}
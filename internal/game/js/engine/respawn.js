@@ -0,0 +1,21 @@
+// Clicks the respawn button, or respawns through the hero object
+function () {
+	try {
+		let btn = document.querySelector('.respawn-button, [data-action="respawn"]');
+		if (btn) {
+			btn.click();
+			return true;
+		}
+
+		// Try game API
+		if (Engine.hero && Engine.hero.respawn) {
+			Engine.hero.respawn();
+			return true;
+		}
+
+		return false;
+	} catch (e) {
+		console.error("Error respawning:", e);
+		return false;
+	}
+}
@@ -0,0 +1,5 @@
+// An open merchant sets Engine.shop, the shop window shows it too
+function () {
+	return !!((Engine.shop && Engine.shop.d && Engine.shop.d.id) ||
+		document.querySelector('.shop-window'));
+}
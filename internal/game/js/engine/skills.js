@@ -0,0 +1,25 @@
+// Lists the hero's battle skills with turns of cooldown left and mana cost
+function () {
+	try {
+		let list = (Engine.skills && (Engine.skills.skills || (Engine.skills.getSkills && Engine.skills.getSkills()))) || {};
+		let skills = [];
+
+		for (let id in list) {
+			let s = list[id];
+			let d = s && (s.d || s);
+			if (!d) continue;
+
+			skills.push({
+				id: String(d.id ?? id),
+				name: d.name,
+				cooldown: d.cd || d.cooldown || 0,
+				manaCost: d.mp || d.mana || d.cost || 0
+			});
+		}
+
+		return skills;
+	} catch (e) {
+		console.error("Error getting skills:", e);
+		return null;
+	}
+}
@@ -0,0 +1,19 @@
+// Opens a dialog with an NPC through the game request, or the NPC object
+function (npcId) {
+	try {
+		if (window._g) {
+			window._g("talk&id=" + npcId);
+			return true;
+		}
+		let npcList = (Engine.npcs && Engine.npcs.check && Engine.npcs.check()) || {};
+		let npc = npcList[npcId];
+		if (npc && npc.talk) {
+			npc.talk();
+			return true;
+		}
+		return false;
+	} catch (e) {
+		console.error("Error talking to NPC:", e);
+		return false;
+	}
+}
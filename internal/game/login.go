@@ -0,0 +1,22 @@
+package game
+
+import "fmt"
+
+// LoggedIn checks whether a saved session already got us into the game
+func (c *Client) LoggedIn() (bool, error) {
+	var loggedIn bool
+	if err := c.call(opLoggedIn, &loggedIn); err != nil {
+		return false, err
+	}
+	return loggedIn, nil
+}
+
+// SubmitLogin fills in and submits the login form, reporting whether the
+// form was found
+func (c *Client) SubmitLogin(username, password string) (bool, error) {
+	var submitted bool
+	if err := c.call(opLogin, &submitted, username, password); err != nil {
+		return false, fmt.Errorf("failed to submit login form: %w", err)
+	}
+	return submitted, nil
+}
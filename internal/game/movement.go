@@ -0,0 +1,94 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Movement errors returned by MoveToAndWait
+var (
+	ErrBlocked    = errors.New("movement blocked")
+	ErrTimeout    = errors.New("movement timed out")
+	ErrMapChanged = errors.New("map changed while moving")
+)
+
+const (
+	movePollInterval    = 250 * time.Millisecond
+	minNoProgressWindow = 3 * time.Second // shortest wait for the hero to get closer
+	noProgressPolls     = 3               // state polls the hero gets to show progress
+	minProgress         = 2.0             // distance gain that counts as progress
+)
+
+// Tolerances and timeouts shared by callers of MoveToAndWait
+const (
+	ArriveTolerance = 20.0 // how close counts as having reached a point
+	NPCTolerance    = 48.0 // NPCs block their own spot, so stopping next to them counts
+	NPCWalkTimeout  = 30 * time.Second
+)
+
+// MoveToAndWait moves the hero and watches its position until it is within
+// tolerance of x/y. It fails with ErrBlocked when the hero stops getting
+// closer, ErrTimeout when the timeout passes and ErrMapChanged when the
+// hero ends up on another map (e.g. stepped on a portal).
+func (c *Client) MoveToAndWait(x, y, tolerance float64, timeout time.Duration) error {
+	start := c.stateMgr.GetHero()
+	if math.Hypot(start.X-x, start.Y-y) <= tolerance {
+		c.stateMgr.GoalReached()
+		return nil
+	}
+
+	if err := c.MoveTo(x, y); err != nil {
+		return err
+	}
+
+	best := math.Hypot(start.X-x, start.Y-y)
+	lastProgress := time.Now()
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(movePollInterval)
+
+		hero := c.stateMgr.GetHero()
+		if hero.MapID != start.MapID {
+			return fmt.Errorf("%w: now on %s", ErrMapChanged, hero.MapID)
+		}
+
+		dist := math.Hypot(hero.X-x, hero.Y-y)
+		if dist <= tolerance {
+			c.stateMgr.GoalReached()
+			return nil
+		}
+
+		if dist < best-minProgress {
+			best = dist
+			lastProgress = time.Now()
+			continue
+		}
+		if time.Since(lastProgress) > c.noProgressWindow() {
+			c.log.WithFields(logrus.Fields{
+				"x":        x,
+				"y":        y,
+				"distance": dist,
+			}).Debug("No movement progress")
+			return fmt.Errorf("%w at %.0f,%.0f (%.0f from target)", ErrBlocked, hero.X, hero.Y, dist)
+		}
+	}
+
+	return fmt.Errorf("%w after %v", ErrTimeout, timeout)
+}
+
+// SetPollInterval tells the client how often the state poller refreshes
+// the hero position, so walks aren't given up between two polls
+func (c *Client) SetPollInterval(d time.Duration) {
+	c.pollInterval.Store(int64(d))
+}
+
+// noProgressWindow is how long the hero may go without getting closer
+// before MoveToAndWait gives up: a few state polls, at least minNoProgressWindow
+func (c *Client) noProgressWindow() time.Duration {
+	return max(minNoProgressWindow, noProgressPolls*time.Duration(c.pollInterval.Load()))
+}
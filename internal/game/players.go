@@ -0,0 +1,62 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Player represents another player's character on the map
+type Player struct {
+	ID       string
+	Nick     string
+	Level    int
+	Clan     string
+	X        float64
+	Y        float64
+	InBattle bool
+	TargetID string // mob the player is fighting, if the game exposes it
+}
+
+// playerResult is one entry of the players script result
+type playerResult struct {
+	ID       string  `json:"id"`
+	Nick     string  `json:"nick"`
+	Level    int     `json:"level,omitempty"`
+	Clan     string  `json:"clan,omitempty"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	InBattle bool    `json:"inBattle,omitempty"`
+	TargetID string  `json:"targetId,omitempty"`
+}
+
+// GetPlayers retrieves other players visible on the map
+func (c *Client) GetPlayers() ([]*Player, error) {
+	var raw json.RawMessage
+	if err := c.call(opPlayers, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get players: %w", err)
+	}
+
+	return c.decodePlayers(raw), nil
+}
+
+// decodePlayers turns a players script result into Players
+func (c *Client) decodePlayers(raw json.RawMessage) []*Player {
+	result, report := decodeList[playerResult](opPlayers, raw)
+	c.recordList(report)
+
+	players := make([]*Player, 0, len(result))
+	for _, p := range result {
+		players = append(players, &Player{
+			ID:       p.ID,
+			Nick:     p.Nick,
+			Level:    p.Level,
+			Clan:     p.Clan,
+			X:        p.X,
+			Y:        p.Y,
+			InBattle: p.InBattle,
+			TargetID: p.TargetID,
+		})
+	}
+
+	return players
+}
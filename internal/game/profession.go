@@ -0,0 +1,41 @@
+package game
+
+import "strings"
+
+// Profession is the hero's character class. The config package keeps its
+// own list of the names to validate settings against.
+type Profession string
+
+const (
+	Warrior     Profession = "warrior"
+	Mage        Profession = "mage"
+	Hunter      Profession = "hunter"
+	Paladin     Profession = "paladin"
+	BladeDancer Profession = "blade_dancer"
+	Tracker     Profession = "tracker"
+)
+
+// professionCodes maps the one-letter codes the game uses
+var professionCodes = map[string]Profession{
+	"w": Warrior,
+	"m": Mage,
+	"h": Hunter,
+	"p": Paladin,
+	"b": BladeDancer,
+	"t": Tracker,
+}
+
+// ParseProfession accepts a game code ("w", "m"...) or a profession name,
+// returning "" for anything else
+func ParseProfession(s string) Profession {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if p, ok := professionCodes[s]; ok {
+		return p
+	}
+	for _, p := range professionCodes {
+		if string(p) == s {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quest is an active quest from the quest log
+type Quest struct {
+	ID         string
+	Name       string
+	Objectives []QuestObjective
+}
+
+// QuestObjective is one step of a quest with its progress counter
+type QuestObjective struct {
+	Text     string
+	Current  int
+	Required int // 0 if the objective has no counter
+}
+
+// Done reports whether the objective's counter is full
+func (o QuestObjective) Done() bool {
+	return o.Required > 0 && o.Current >= o.Required
+}
+
+// Complete reports whether every counted objective is done
+func (q *Quest) Complete() bool {
+	counted := 0
+	for _, o := range q.Objectives {
+		if o.Required == 0 {
+			continue
+		}
+		counted++
+		if !o.Done() {
+			return false
+		}
+	}
+	return counted > 0
+}
+
+// progressPattern matches counters like "3/10" or "3 / 10"
+var progressPattern = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+
+// GetQuests retrieves active quests from the quest log
+func (c *Client) GetQuests() ([]*Quest, error) {
+	var result []struct {
+		ID         string   `json:"id"`
+		Name       string   `json:"name"`
+		Objectives []string `json:"objectives"`
+	}
+	if err := c.call(opQuests, &result); err != nil {
+		return nil, fmt.Errorf("failed to get quests: %w", err)
+	}
+
+	quests := make([]*Quest, 0, len(result))
+	for _, r := range result {
+		q := &Quest{ID: r.ID, Name: r.Name}
+		for _, text := range r.Objectives {
+			q.Objectives = append(q.Objectives, parseObjective(text))
+		}
+		quests = append(quests, q)
+	}
+
+	return quests, nil
+}
+
+// parseObjective reads the progress counter out of an objective's text
+func parseObjective(text string) QuestObjective {
+	o := QuestObjective{Text: strings.TrimSpace(text)}
+	if m := progressPattern.FindStringSubmatch(text); m != nil {
+		o.Current, _ = strconv.Atoi(m[1])
+		o.Required, _ = strconv.Atoi(m[2])
+	}
+	return o
+}
@@ -0,0 +1,321 @@
+package game
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// emptyDocument stands in for a page without any of the game's windows
+const emptyDocument = `
+globalThis.window = globalThis;
+globalThis.document = {
+	querySelector: function () { return null; },
+	querySelectorAll: function () { return []; }
+};
+`
+
+// classicPage mocks the classic interface globals
+const classicPage = `
+window.calls = [];
+window._g = function (q) { window.calls.push(q); };
+window.hero = {x: 10, y: 12, nick: "Hero", respawn: function () { window.calls.push("respawn"); }};
+window.npcs = {};
+window.g = {
+	loots: {"7": {name: "Wolf Pelt", stat: "rarity=unique", pr: 20}},
+	item: {
+		"1": {name: "Bread", loc: "g", cl: 7, pr: 5, stat: "amount=3"},
+		"2": {name: "Axe", loc: "m", x: 11, y: 12, pr: 40},
+		"3": {name: "Far Axe", loc: "m", x: 40, y: 12},
+		"4": {name: "Potion", loc: "n", own: 9, pr: 30},
+		"5": {name: "Other Shop", loc: "n", own: 8}
+	},
+	shop: {id: 9},
+	bags: [[20], [10]],
+	talk: {id: 5, name: "Guard", text: "Halt!", replies: [{id: 11, text: "Let me pass"}, {id: 12, text: "Bye"}]},
+	quests: {"3": {name: "Wolf Hunt", objectives: ["Kill wolves 2/10"]}},
+	chat: {messages: [
+		{ts: 100, k: 0, n: "Old", t: "old news"},
+		{ts: 2000, k: 1, n: "Anna", t: "hi"}
+	]}
+};
+`
+
+// enginePage mocks the newer interface, where game objects keep their data in d
+const enginePage = `
+window.calls = [];
+window._g = function (q) { window.calls.push(q); };
+window.Engine = {
+	hero: {d: {x: 10, y: 12, nick: "Hero"}, respawn: function () { window.calls.push("respawn"); }},
+	npcs: {check: function () { return {}; }},
+	loots: {items: {"7": {d: {name: "Wolf Pelt", stat: "rarity=unique", pr: 20}}}},
+	items: {items: {
+		"1": {d: {name: "Bread", loc: "g", cl: 7, pr: 5, stat: "amount=3"}},
+		"2": {d: {name: "Axe", loc: "m", x: 11, y: 12, pr: 40}},
+		"3": {d: {name: "Far Axe", loc: "m", x: 40, y: 12}},
+		"4": {d: {name: "Potion", loc: "n", own: 9, pr: 30}},
+		"5": {d: {name: "Other Shop", loc: "n", own: 8}}
+	}},
+	shop: {d: {id: 9}},
+	bags: {list: [[20], [10]]},
+	dialogue: {d: {id: 5, name: "Guard", text: "Halt!", replies: [{id: 11, text: "Let me pass"}, {id: 12, text: "Bye"}]}},
+	quests: {list: {"3": {d: {name: "Wolf Hunt", objectives: [{text: "Kill wolves 2/10"}]}}}},
+	chat: {messages: [
+		{d: {ts: 100, k: 0, n: "Old", t: "old news"}},
+		{d: {ts: 2000, k: 1, n: "Anna", t: "hi"}}
+	]}
+};
+`
+
+// scriptCall is one operation run on a mock page
+type scriptCall struct {
+	op   string
+	args []interface{}
+}
+
+// runOnPage runs an adapter's scripts one after another on a mock page in
+// node and returns their results. A final call returns window.calls, the
+// game requests the scripts sent.
+func runOnPage(t *testing.T, a EngineAdapter, page string, calls ...scriptCall) (results []json.RawMessage, requests []string) {
+	t.Helper()
+
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not installed")
+	}
+
+	var src strings.Builder
+	src.WriteString(emptyDocument)
+	src.WriteString(page)
+	src.WriteString("const results = [];\n")
+	for _, call := range calls {
+		fn, err := adapterScript(a, call.op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.args == nil {
+			call.args = []interface{}{}
+		}
+		args, err := json.Marshal(call.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src.WriteString("results.push((" + fn + "\n)(..." + string(args) + ") ?? null);\n")
+	}
+	src.WriteString("results.push(window.calls || []);\n")
+	src.WriteString("process.stdout.write(JSON.stringify(results));\n")
+
+	// Scripts log caught errors to stderr, only stdout carries the results
+	cmd := exec.Command(node, "-e", src.String())
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("node failed: %v\n%s", err, stderr.String())
+	}
+
+	if err := json.Unmarshal(out, &results); err != nil {
+		t.Fatalf("bad output %q: %v", out, err)
+	}
+	if err := json.Unmarshal(results[len(results)-1], &requests); err != nil {
+		t.Fatalf("bad game requests %s: %v", results[len(results)-1], err)
+	}
+	return results[:len(results)-1], requests
+}
+
+// mockPages pairs each adapter with a page of its interface
+var mockPages = []struct {
+	adapter EngineAdapter
+	page    string
+}{
+	{NewClassicAdapter(), classicPage},
+	{NewEngineInterfaceAdapter(), enginePage},
+}
+
+func TestItemsScript(t *testing.T) {
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, _ := runOnPage(t, mp.adapter, mp.page,
+				scriptCall{opItems, []interface{}{[]string{"window", "ground", "bag", "shop"}}})
+
+			var result struct {
+				Items    json.RawMessage `json:"items"`
+				Capacity int             `json:"capacity"`
+			}
+			if report := decodeStrict(opItems, results[0], &result); !report.OK() {
+				t.Fatalf("items result: %v (%s)", report, results[0])
+			}
+			items, report := decodeList[itemResult](opItems, result.Items)
+			if !report.OK() {
+				t.Fatalf("item fields: %v", report)
+			}
+
+			if result.Capacity != 30 {
+				t.Errorf("capacity = %d, want 30", result.Capacity)
+			}
+
+			got := make(map[string]itemResult)
+			for _, it := range items {
+				got[it.Source+":"+it.Name] = it
+			}
+			for _, want := range []string{"window:Wolf Pelt", "ground:Axe", "bag:Bread", "shop:Potion"} {
+				if _, ok := got[want]; !ok {
+					t.Errorf("missing %s in %v", want, got)
+				}
+			}
+			if len(items) != 4 {
+				t.Errorf("got %d items, want 4: %v", len(items), got)
+			}
+			if it := got["bag:Bread"]; it.Quantity != 3 || it.Type != "7" {
+				t.Errorf("bread = %+v, want quantity 3 and type 7", it)
+			}
+			if it := got["window:Wolf Pelt"]; it.Rarity != "unique" {
+				t.Errorf("pelt rarity = %q, want unique", it.Rarity)
+			}
+		})
+	}
+}
+
+func TestDialogScript(t *testing.T) {
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, _ := runOnPage(t, mp.adapter, mp.page, scriptCall{op: opDialog})
+
+			var dialog dialogResult
+			if err := json.Unmarshal(results[0], &dialog); err != nil {
+				t.Fatal(err)
+			}
+			if dialog.NPCID != "5" || dialog.NPCName != "Guard" || dialog.Text != "Halt!" {
+				t.Errorf("dialog = %+v", dialog)
+			}
+			if len(dialog.Options) != 2 || dialog.Options[0].ReplyID != "11" || dialog.Options[0].Text != "Let me pass" {
+				t.Errorf("options = %+v", dialog.Options)
+			}
+		})
+	}
+}
+
+func TestQuestsScript(t *testing.T) {
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, _ := runOnPage(t, mp.adapter, mp.page, scriptCall{op: opQuests})
+
+			var quests []struct {
+				ID         string   `json:"id"`
+				Name       string   `json:"name"`
+				Objectives []string `json:"objectives"`
+			}
+			if err := json.Unmarshal(results[0], &quests); err != nil {
+				t.Fatal(err)
+			}
+			if len(quests) != 1 || quests[0].ID != "3" || quests[0].Name != "Wolf Hunt" {
+				t.Fatalf("quests = %+v", quests)
+			}
+			if len(quests[0].Objectives) != 1 || quests[0].Objectives[0] != "Kill wolves 2/10" {
+				t.Errorf("objectives = %v", quests[0].Objectives)
+			}
+		})
+	}
+}
+
+func TestChatScript(t *testing.T) {
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			// Game time stamps are in seconds, since is in milliseconds
+			results, _ := runOnPage(t, mp.adapter, mp.page, scriptCall{opChat, []interface{}{1000000}})
+
+			var messages []struct {
+				TS      int64  `json:"ts"`
+				Channel string `json:"channel"`
+				Author  string `json:"author"`
+				Text    string `json:"text"`
+			}
+			if err := json.Unmarshal(results[0], &messages); err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("got %d messages, want only the one after since: %+v", len(messages), messages)
+			}
+			m := messages[0]
+			if m.TS != 2000000 || m.Channel != "1" || m.Author != "Anna" || m.Text != "hi" {
+				t.Errorf("message = %+v", m)
+			}
+		})
+	}
+}
+
+func TestShopOpenAndRespawnScripts(t *testing.T) {
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, requests := runOnPage(t, mp.adapter, mp.page,
+				scriptCall{op: opShopOpen},
+				scriptCall{op: opRespawn})
+
+			if string(results[0]) != "true" {
+				t.Errorf("shop_open = %s, want true", results[0])
+			}
+			if string(results[1]) != "true" {
+				t.Errorf("respawn = %s, want true", results[1])
+			}
+			if len(requests) != 1 || requests[0] != "respawn" {
+				t.Errorf("requests = %v, want the hero respawn", requests)
+			}
+		})
+	}
+}
+
+// TestScriptsStayOnTheirInterface runs every adapter's scripts on the other
+// interface's page, where they must not pick up the other's data. Engine
+// scripts only run once window.Engine exists, so they get an empty one.
+func TestScriptsStayOnTheirInterface(t *testing.T) {
+	pages := map[string]string{
+		"classic": enginePage,
+		"engine":  classicPage + "window.Engine = {};\n",
+	}
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, _ := runOnPage(t, mp.adapter, pages[mp.adapter.Name()],
+				scriptCall{op: opDialog},
+				scriptCall{op: opQuests},
+				scriptCall{opChat, []interface{}{0}},
+				scriptCall{op: opShopOpen})
+
+			for i, want := range []string{"null", "[]", "[]", "false"} {
+				if string(results[i]) != want {
+					t.Errorf("result %d = %s, want %s", i, results[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestHeroScriptWithoutMana reads a warrior, whose game object has no mana
+// fields. The hero must come back with MPMax 0, not a made-up pool, so MP
+// rules stay off.
+func TestHeroScriptWithoutMana(t *testing.T) {
+	warriors := map[string]string{
+		"classic": classicPage + `window.hero = {x: 10, y: 12, nick: "Hero", hp: 50, maxhp: 80, lvl: 12, prof: "w", map: {id: 3}};`,
+		"engine": enginePage + `Engine.hero.d = {x: 10, y: 12, nick: "Hero", lvl: 12, prof: "w", warrior_stats: {hp: 50, maxhp: 80}};
+Engine.map = {d: {id: 3}};`,
+	}
+	for _, mp := range mockPages {
+		t.Run(mp.adapter.Name(), func(t *testing.T) {
+			results, _ := runOnPage(t, mp.adapter, warriors[mp.adapter.Name()], scriptCall{op: opHero})
+
+			c := testClient()
+			c.adapter = mp.adapter
+
+			hero, err := c.decodeHero(results[0])
+			if err != nil {
+				t.Fatalf("decode hero %s: %v", results[0], err)
+			}
+			if hero.MPMax != 0 || hero.MPPercent() != 0 {
+				t.Errorf("hero MP = %d/%d, want no mana", hero.MP, hero.MPMax)
+			}
+			if hero.HP != 50 || hero.HPMax != 80 || hero.MapID != "3" {
+				t.Errorf("hero = %+v", hero)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// shopKeywords identify the dialog option that opens a merchant's shop
+var shopKeywords = []string{"sklep", "handel", "kupi"}
+
+// OpenShop talks to a merchant NPC and waits for its shop window
+func (c *Client) OpenShop(npcID string) error {
+	c.log.WithField("npcId", npcID).Debug("Opening shop...")
+
+	if err := c.TalkTo(npcID); err != nil {
+		return err
+	}
+
+	// Merchants usually greet first, the shop opens from a dialog option
+	chosen := false
+	start := time.Now()
+	for time.Since(start) < dialogTimeout {
+		open, err := c.isShopOpen()
+		if err != nil {
+			return err
+		}
+		if open {
+			return nil
+		}
+
+		if dialog, err := c.GetDialog(); err == nil && dialog != nil && !chosen {
+			if option, ok := findShopOption(dialog); ok {
+				if err := c.chooseOption(dialog, option); err != nil {
+					return err
+				}
+				chosen = true
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return fmt.Errorf("shop window did not open after %v", dialogTimeout)
+}
+
+// findShopOption returns the dialog option that leads to the shop
+func findShopOption(dialog *Dialog) (DialogOption, bool) {
+	for _, o := range dialog.Options {
+		text := strings.ToLower(o.Text)
+		for _, keyword := range shopKeywords {
+			if strings.Contains(text, keyword) {
+				return o, true
+			}
+		}
+	}
+	return DialogOption{}, false
+}
+
+// isShopOpen checks whether a shop window is open
+func (c *Client) isShopOpen() (bool, error) {
+	var open bool
+	if err := c.call(opShopOpen, &open); err != nil {
+		return false, fmt.Errorf("failed to check shop window: %w", err)
+	}
+	return open, nil
+}
+
+// GetShopItems retrieves the items offered by the open shop, Value being the price
+func (c *Client) GetShopItems() ([]*Item, error) {
+	result, err := c.readItems("shop")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shop items: %w", err)
+	}
+
+	return result.Items, nil
+}
+
+// SellItems sells bag items to the open shop
+func (c *Client) SellItems(itemIDs []string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	c.log.WithField("count", len(itemIDs)).Debug("Selling items...")
+
+	return c.shopRequest("shop&sell=" + strings.Join(itemIDs, ","))
+}
+
+// BuyItem buys the given quantity of a shop item
+func (c *Client) BuyItem(itemID string, quantity int) error {
+	c.log.WithFields(logrus.Fields{
+		"itemId":   itemID,
+		"quantity": quantity,
+	}).Debug("Buying item...")
+
+	return c.shopRequest(fmt.Sprintf("shop&buy=%s,%d", itemID, quantity))
+}
+
+// CloseShop closes the shop window
+func (c *Client) CloseShop() error {
+	return c.shopRequest("shop&close=1")
+}
+
+// shopRequest sends a shop command to the game
+func (c *Client) shopRequest(request string) error {
+	var success bool
+	if err := c.call(opRequest, &success, request); err != nil {
+		return fmt.Errorf("shop request failed: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("shop request not supported")
+	}
+
+	return nil
+}
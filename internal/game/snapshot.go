@@ -0,0 +1,148 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// opSnapshot names the composed snapshot script in field reports
+const opSnapshot = "snapshot"
+
+// snapshotOps are the scripts a snapshot combines, each result stored
+// under the op name
+var snapshotOps = []string{opHero, opMobs, opPlayers, opBattle, opConnected}
+
+// Snapshot is one consistent read of the game world, taken in a single
+// script evaluation
+type Snapshot struct {
+	Seq       uint64 // increases with every snapshot taken by the client
+	Taken     time.Time
+	Hero      *HeroState // nil while the hero isn't loaded
+	Mobs      []*Mob
+	Players   []*Player
+	InBattle  bool
+	Connected bool
+}
+
+// snapshotResult is what the composed snapshot script returns
+type snapshotResult struct {
+	Hero      json.RawMessage `json:"hero,omitempty"`
+	Mobs      json.RawMessage `json:"mobs"`
+	Players   json.RawMessage `json:"players"`
+	Battle    bool            `json:"battle"`
+	Connected bool            `json:"connected"`
+}
+
+// Snapshot reads hero, mobs, players, battle and connection state at once
+func (c *Client) Snapshot() (*Snapshot, error) {
+	fn, err := c.snapshotScript()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	if err := c.browser.Call(fn, &raw); err != nil {
+		return nil, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+
+	var result snapshotResult
+	report := decodeStrict(opSnapshot, raw, &result)
+	c.record(report)
+	if !report.OK() {
+		return nil, report
+	}
+
+	snap := &Snapshot{
+		Seq:       c.seq.Add(1),
+		Taken:     time.Now(),
+		InBattle:  result.Battle,
+		Connected: result.Connected,
+	}
+
+	// Without a hero the rest of the world is meaningless
+	hero, err := c.decodeHero(result.Hero)
+	if err != nil {
+		return snap, nil
+	}
+	hero.InCombat = hero.InCombat || result.Battle
+
+	snap.Hero = hero
+	snap.Mobs = c.decodeMobs(result.Mobs)
+	snap.Players = c.decodePlayers(result.Players)
+	return snap, nil
+}
+
+// Changes compares the snapshot with the previous one: mobs is set when
+// live mobs appeared or disappeared, battle when a battle started or
+// ended, hero when the hero lost HP, died, revived or changed map
+func (s *Snapshot) Changes(prev *Snapshot) (mobs, battle, hero bool) {
+	if s.Hero == nil {
+		return false, false, false
+	}
+	if prev == nil || prev.Hero == nil {
+		return true, s.InBattle, true
+	}
+
+	battle = s.InBattle != prev.InBattle
+	hero = s.Hero.HP < prev.Hero.HP || s.Hero.Dead != prev.Hero.Dead || s.Hero.MapID != prev.Hero.MapID
+	mobs = !sameLiveMobs(s.Mobs, prev.Mobs)
+	return mobs, battle, hero
+}
+
+// LiveMobs returns the number of mobs alive in the snapshot
+func (s *Snapshot) LiveMobs() int {
+	n := 0
+	for _, m := range s.Mobs {
+		if m.Alive {
+			n++
+		}
+	}
+	return n
+}
+
+func sameLiveMobs(a, b []*Mob) bool {
+	live := make(map[string]bool)
+	for _, m := range a {
+		if m.Alive {
+			live[m.ID] = true
+		}
+	}
+	n := 0
+	for _, m := range b {
+		if !m.Alive {
+			continue
+		}
+		if !live[m.ID] {
+			return false
+		}
+		n++
+	}
+	return n == len(live)
+}
+
+// snapshotScript composes the adapter's scripts into one function calling
+// each of them, built once per detected adapter
+func (c *Client) snapshotScript() (string, error) {
+	name := ""
+	if c.adapter != nil {
+		name = c.adapter.Name()
+	}
+	if c.snapshotFn != "" && c.snapshotFor == name {
+		return c.snapshotFn, nil
+	}
+
+	fields := make([]string, 0, len(snapshotOps))
+	for _, op := range snapshotOps {
+		src, err := c.script(op)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("\t\t%s: (%s\n\t\t)()", op, strings.TrimSpace(src)))
+	}
+
+	c.snapshotFn = "function () {\n\treturn {\n" + strings.Join(fields, ",\n") + "\n\t};\n}"
+	c.snapshotFor = name
+	return c.snapshotFn, nil
+}
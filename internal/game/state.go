@@ -1,6 +1,8 @@
 package game
 
 import (
+	"math"
+	"strings"
 	"sync"
 	"time"
 )
@@ -18,6 +20,11 @@ const (
 	PhaseRecover
 	PhaseDisconnected
 	PhaseShutdown
+	PhaseRest
+	PhaseTown
+	PhaseQuest
+	PhasePaused
+	PhaseBattle
 )
 
 func (p BotPhase) String() string {
@@ -40,6 +47,16 @@ func (p BotPhase) String() string {
 		return "DISCONNECTED"
 	case PhaseShutdown:
 		return "SHUTDOWN"
+	case PhaseRest:
+		return "RESTING"
+	case PhaseTown:
+		return "TOWN"
+	case PhaseQuest:
+		return "QUEST"
+	case PhasePaused:
+		return "PAUSED"
+	case PhaseBattle:
+		return "BATTLE"
 	default:
 		return "UNKNOWN"
 	}
@@ -50,17 +67,42 @@ type HeroState struct {
 	X        float64
 	Y        float64
 	MapID    string
+	Nick     string
 	HP       int
 	HPMax    int
 	MP       int
 	MPMax    int
 	Level    int
+	Profession Profession // "" when the game doesn't expose it
 	Exp      int
+	ExpKnown bool // false when the game didn't report exp
+	ExpNext  int  // exp at which the next level is reached, 0 when unknown
+	Gold     int
 	InCombat bool
 	Dead     bool
+	Effects  []Effect // active buffs and debuffs
 	LastUpdate time.Time
 }
 
+// Effect is a buff or debuff on the hero
+type Effect struct {
+	Name      string
+	Remaining time.Duration // 0 when permanent or unknown
+	Stacks    int
+	Debuff    bool
+}
+
+// Effect finds an active effect by case-insensitive name substring
+func (h *HeroState) Effect(name string) (Effect, bool) {
+	name = strings.ToLower(name)
+	for _, e := range h.Effects {
+		if strings.Contains(strings.ToLower(e.Name), name) {
+			return e, true
+		}
+	}
+	return Effect{}, false
+}
+
 // HPPercent returns HP as a percentage
 func (h *HeroState) HPPercent() int {
 	if h.HPMax == 0 {
@@ -69,7 +111,7 @@ func (h *HeroState) HPPercent() int {
 	return (h.HP * 100) / h.HPMax
 }
 
-// MPPercent returns MP as a percentage
+// MPPercent returns MP as a percentage, 0 for heroes without mana (MPMax 0)
 func (h *HeroState) MPPercent() int {
 	if h.MPMax == 0 {
 		return 0
@@ -88,6 +130,8 @@ type Mob struct {
 	HPMax      int
 	Alive      bool
 	Attackable bool
+	Aggressive bool
+	Contested  bool // another player is already fighting it
 }
 
 // ConnectionState represents connection status
@@ -102,12 +146,39 @@ type StateManager struct {
 	mu              sync.RWMutex
 	hero            *HeroState
 	mobs            []*Mob
+	players         []*Player
+	inventory       Inventory
 	connection      ConnectionState
 	phase           BotPhase
 	positionHistory []PositionRecord
 	actionCount     int
+	pausedUntil     time.Time
+	pauseReason     string
+	holdReason      string // pause without an end, lifted by Release
+	stats           SessionStats
+	moveGoal        *MoveGoal
+	lastGood        *PositionRecord
+	lastGoodMap     string
+	pendingExp      int       // exp gained in the current battle, see BattleStarted
+	lastKills       []string  // mobs of the last battle won, credited with late exp
+	lastKillsAt     time.Time
+	inBattle        bool
+	seq             uint64
+}
+
+// MoveGoal is where the bot last asked the hero to walk to
+type MoveGoal struct {
+	X     float64
+	Y     float64
+	MapID string
+	Since time.Time
 }
 
+const (
+	positionHistoryAge = 2 * time.Minute // longest stuck window supported (timing.stuckWindow), whatever the poll rate
+	goalReachedRadius  = 20.0            // a goal this close counts as reached
+)
+
 // PositionRecord tracks position for stuck detection
 type PositionRecord struct {
 	X         float64
@@ -120,9 +191,11 @@ func NewStateManager() *StateManager {
 	return &StateManager{
 		hero:            &HeroState{},
 		mobs:            make([]*Mob, 0),
+		players:         make([]*Player, 0),
 		connection:      ConnectionState{Connected: true},
 		phase:           PhaseStartup,
-		positionHistory: make([]PositionRecord, 0, 10),
+		positionHistory: make([]PositionRecord, 0, 128),
+		stats:           SessionStats{Started: time.Now()},
 	}
 }
 
@@ -130,8 +203,45 @@ func NewStateManager() *StateManager {
 func (sm *StateManager) UpdateHero(hero *HeroState) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
+	sm.setHero(hero)
+}
+
+// ApplySnapshot updates hero, mobs, players and connection in one step, so
+// readers never see a mix of two polls. Without a hero only the
+// connection is updated.
+func (sm *StateManager) ApplySnapshot(snap *Snapshot) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	
+	sm.seq = snap.Seq
+	sm.setConnection(snap.Connected)
+	if snap.Hero == nil {
+		return
+	}
 	
+	sm.setHero(snap.Hero)
+	sm.mobs = snap.Mobs
+	sm.players = snap.Players
+	sm.inBattle = snap.InBattle
+}
+
+// Seq returns the sequence number of the last applied snapshot
+func (sm *StateManager) Seq() uint64 {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.seq
+}
+
+// InBattle reports whether the last snapshot saw a battle going on
+func (sm *StateManager) InBattle() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.inBattle
+}
+
+func (sm *StateManager) setHero(hero *HeroState) {
 	hero.LastUpdate = time.Now()
+	sm.trackExp(sm.hero, hero)
 	sm.hero = hero
 	
 	// Track position for stuck detection
@@ -141,10 +251,20 @@ func (sm *StateManager) UpdateHero(hero *HeroState) {
 		Timestamp: time.Now(),
 	})
 	
-	// Keep only last 10 positions
-	if len(sm.positionHistory) > 10 {
+	// Keep only the recent positions
+	for len(sm.positionHistory) > 0 && time.Since(sm.positionHistory[0].Timestamp) > positionHistoryAge {
 		sm.positionHistory = sm.positionHistory[1:]
 	}
+	
+	// A fight or a map change ends the walk; arriving completes it
+	if goal := sm.moveGoal; goal != nil {
+		switch {
+		case hero.InCombat || hero.MapID != goal.MapID:
+			sm.moveGoal = nil
+		case math.Hypot(hero.X-goal.X, hero.Y-goal.Y) <= goalReachedRadius:
+			sm.reachGoal()
+		}
+	}
 }
 
 // GetHero returns a copy of the hero state
@@ -154,6 +274,25 @@ func (sm *StateManager) GetHero() HeroState {
 	return *sm.hero
 }
 
+// Effects returns a copy of the hero's active buffs and debuffs
+func (sm *StateManager) Effects() []Effect {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	result := make([]Effect, len(sm.hero.Effects))
+	copy(result, sm.hero.Effects)
+	return result
+}
+
+// HasEffect reports whether the hero has an effect matching the name
+func (sm *StateManager) HasEffect(name string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	_, ok := sm.hero.Effect(name)
+	return ok
+}
+
 // UpdateMobs updates mob list
 func (sm *StateManager) UpdateMobs(mobs []*Mob) {
 	sm.mu.Lock()
@@ -171,6 +310,53 @@ func (sm *StateManager) GetMobs() []*Mob {
 	return result
 }
 
+// UpdatePlayers updates the list of other players
+func (sm *StateManager) UpdatePlayers(players []*Player) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.players = players
+}
+
+// GetPlayers returns a copy of the player list
+func (sm *StateManager) GetPlayers() []*Player {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	result := make([]*Player, len(sm.players))
+	copy(result, sm.players)
+	return result
+}
+
+// UpdateInventory replaces the cached inventory
+func (sm *StateManager) UpdateInventory(inv *Inventory) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.inventory = *inv
+}
+
+// AddItems adds freshly looted items to the cached inventory
+func (sm *StateManager) AddItems(items []*Item) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	
+	for _, it := range items {
+		added := *it
+		added.Source = "bag"
+		sm.inventory.Items = append(sm.inventory.Items, &added)
+	}
+}
+
+// GetInventory returns a copy of the cached inventory
+func (sm *StateManager) GetInventory() Inventory {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	inv := sm.inventory
+	inv.Items = make([]*Item, len(sm.inventory.Items))
+	copy(inv.Items, sm.inventory.Items)
+	return inv
+}
+
 // SetPhase sets the current bot phase
 func (sm *StateManager) SetPhase(phase BotPhase) {
 	sm.mu.Lock()
@@ -185,6 +371,48 @@ func (sm *StateManager) GetPhase() BotPhase {
 	return sm.phase
 }
 
+// Pause stops the bot for the given duration. A longer pause already in
+// effect is kept.
+func (sm *StateManager) Pause(d time.Duration, reason string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	
+	until := time.Now().Add(d)
+	if until.After(sm.pausedUntil) {
+		sm.pausedUntil = until
+		sm.pauseReason = reason
+	}
+}
+
+// Hold pauses the bot until Release is called. Timed pauses are kept and
+// run on after the release.
+func (sm *StateManager) Hold(reason string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.holdReason = reason
+}
+
+// Release lifts a pause set by Hold
+func (sm *StateManager) Release() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.holdReason = ""
+}
+
+// Paused reports whether the bot is paused and why
+func (sm *StateManager) Paused() (bool, string) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	if sm.holdReason != "" {
+		return true, sm.holdReason
+	}
+	if time.Now().Before(sm.pausedUntil) {
+		return true, sm.pauseReason
+	}
+	return false, ""
+}
+
 // IncrementAction increments action counter
 func (sm *StateManager) IncrementAction() int {
 	sm.mu.Lock()
@@ -200,40 +428,100 @@ func (sm *StateManager) GetActionCount() int {
 	return sm.actionCount
 }
 
-// IsStuck checks if character is stuck based on position history
-func (sm *StateManager) IsStuck(threshold float64, duration time.Duration) bool {
+// SetMoveGoal records that the hero was sent walking to x/y
+func (sm *StateManager) SetMoveGoal(x, y float64) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.moveGoal = &MoveGoal{X: x, Y: y, MapID: sm.hero.MapID, Since: time.Now()}
+}
+
+// GoalReached clears the move goal and remembers the hero position as a
+// known good point to fall back to when stuck
+func (sm *StateManager) GoalReached() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.reachGoal()
+}
+
+func (sm *StateManager) reachGoal() {
+	sm.moveGoal = nil
+	sm.lastGood = &PositionRecord{X: sm.hero.X, Y: sm.hero.Y, Timestamp: time.Now()}
+	sm.lastGoodMap = sm.hero.MapID
+}
+
+// ClearMoveGoal forgets the move goal without marking it reached
+func (sm *StateManager) ClearMoveGoal() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.moveGoal = nil
+}
+
+// GetMoveGoal returns the current move goal, if any
+func (sm *StateManager) GetMoveGoal() (MoveGoal, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.moveGoal == nil {
+		return MoveGoal{}, false
+	}
+	return *sm.moveGoal, true
+}
+
+// LastGoodPoint returns the last reached move goal on the current map
+func (sm *StateManager) LastGoodPoint() (PositionRecord, bool) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
+	if sm.lastGood == nil || sm.lastGoodMap != sm.hero.MapID {
+		return PositionRecord{}, false
+	}
+	return *sm.lastGood, true
+}
+
+// IsStuck reports whether the hero was sent somewhere but got less than
+// threshold closer to it over the window. Standing still on purpose
+// (fighting, resting, paused, no goal) never counts as stuck.
+func (sm *StateManager) IsStuck(threshold float64, window time.Duration) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	switch sm.phase {
+	case PhaseHunt, PhaseNavigate, PhaseRecover, PhaseTown, PhaseQuest:
+	default:
+		return false
+	}
 	
+	goal := sm.moveGoal
+	if goal == nil || sm.hero.InCombat || sm.hero.Dead || time.Since(goal.Since) < window {
+		return false
+	}
 	if len(sm.positionHistory) < 2 {
 		return false
 	}
 	
-	// Check if we've been in the same general area for too long
-	recent := sm.positionHistory[len(sm.positionHistory)-1]
-	for i := len(sm.positionHistory) - 2; i >= 0; i-- {
-		pos := sm.positionHistory[i]
-		if time.Since(pos.Timestamp) > duration {
+	// Compare the distance to the goal at the start of the window with now
+	var start *PositionRecord
+	for i := range sm.positionHistory {
+		if time.Since(sm.positionHistory[i].Timestamp) <= window {
+			start = &sm.positionHistory[i]
 			break
 		}
-		
-		dx := recent.X - pos.X
-		dy := recent.Y - pos.Y
-		dist := dx*dx + dy*dy
-		
-		if dist < threshold*threshold {
-			return true
-		}
+	}
+	if start == nil {
+		return false
 	}
 	
-	return false
+	recent := sm.positionHistory[len(sm.positionHistory)-1]
+	progress := math.Hypot(start.X-goal.X, start.Y-goal.Y) - math.Hypot(recent.X-goal.X, recent.Y-goal.Y)
+	return progress < threshold
 }
 
 // UpdateConnection updates connection state
 func (sm *StateManager) UpdateConnection(connected bool) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	
+	sm.setConnection(connected)
+}
+
+func (sm *StateManager) setConnection(connected bool) {
 	sm.connection.Connected = connected
 	sm.connection.LastCheck = time.Now()
 	
@@ -0,0 +1,125 @@
+package game
+
+import "time"
+
+// SessionStats holds counters collected during a bot session
+type SessionStats struct {
+	Started     time.Time
+	Rests       int
+	RestTime    time.Duration
+	Kills       int
+	ItemsLooted int
+	TownTrips   int
+	GoldEarned  int
+	GoldSpent   int
+	Stuck       int               // times the hero was detected stuck
+	Unstick     map[string]int    // recovery attempts per escalation step
+	Exp         int               // exp gained this session
+	MobExp      map[string]MobExp // kills and exp per mob name
+}
+
+// Uptime returns how long the session has been running
+func (s SessionStats) Uptime() time.Duration {
+	return time.Since(s.Started)
+}
+
+// RecordRest adds a finished rest to the session stats
+func (sm *StateManager) RecordRest(d time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.stats.Rests++
+	sm.stats.RestTime += d
+}
+
+// BattleStarted drops exp gained outside battles (quests, turn-ins), so
+// only the exp of the battle about to start is credited to its kills
+func (sm *StateManager) BattleStarted() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.pendingExp = 0
+	sm.lastKills = nil
+}
+
+// RecordKills counts the mobs killed in a battle and splits the exp gained
+// since it started evenly between them. Exp read shortly afterwards is
+// split between them too, see trackExp.
+func (sm *StateManager) RecordKills(mobNames []string) {
+	if len(mobNames) == 0 {
+		return
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.stats.Kills += len(mobNames)
+
+	if sm.stats.MobExp == nil {
+		sm.stats.MobExp = make(map[string]MobExp)
+	}
+	for _, name := range mobNames {
+		m := sm.stats.MobExp[name]
+		m.Kills++
+		sm.stats.MobExp[name] = m
+	}
+	sm.creditKills(mobNames, sm.pendingExp)
+	sm.pendingExp = 0
+	sm.lastKills = mobNames
+	sm.lastKillsAt = time.Now()
+}
+
+// creditKills splits exp evenly between mobs already counted as killed
+func (sm *StateManager) creditKills(mobNames []string, exp int) {
+	share := exp / len(mobNames)
+	rest := exp % len(mobNames)
+	for i, name := range mobNames {
+		m := sm.stats.MobExp[name]
+		m.Exp += share
+		if i == 0 {
+			m.Exp += rest
+		}
+		sm.stats.MobExp[name] = m
+	}
+}
+
+// RecordLoot counts picked up items
+func (sm *StateManager) RecordLoot(count int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.stats.ItemsLooted += count
+}
+
+// RecordTownTrip counts a finished town trip and the gold it moved
+func (sm *StateManager) RecordTownTrip(earned, spent int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.stats.TownTrips++
+	sm.stats.GoldEarned += earned
+	sm.stats.GoldSpent += spent
+}
+
+// RecordUnstick counts a stuck detection and the recovery step tried for it
+func (sm *StateManager) RecordUnstick(step string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.stats.Stuck++
+	if sm.stats.Unstick == nil {
+		sm.stats.Unstick = make(map[string]int)
+	}
+	sm.stats.Unstick[step]++
+}
+
+// GetStats returns a copy of the session stats
+func (sm *StateManager) GetStats() SessionStats {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	stats := sm.stats
+	stats.Unstick = make(map[string]int, len(sm.stats.Unstick))
+	for step, n := range sm.stats.Unstick {
+		stats.Unstick[step] = n
+	}
+	stats.MobExp = make(map[string]MobExp, len(sm.stats.MobExp))
+	for name, m := range sm.stats.MobExp {
+		stats.MobExp[name] = m
+	}
+	return stats
+}
@@ -0,0 +1,296 @@
+package heatmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/game"
+)
+
+// CellSize is the edge length of a heatmap cell in map units (one tile)
+const CellSize = 32.0
+
+// deathPolls is how many observations in a row a mob must be missing from
+// to count as dead, so a poll that lost it doesn't record a fake respawn
+const deathPolls = 2
+
+// Heatmap records where and when mobs were seen alive and died, per map
+type Heatmap struct {
+	mu   sync.Mutex
+	path string
+	Maps map[string]*MapData `json:"maps"`
+
+	// last observation, to spot appearances and deaths
+	lastMap string
+	prev    map[string]*game.Mob
+	missing map[string]int // observations in a row a tracked mob was missing from
+}
+
+// MapData holds the cells of one map, keyed by "x,y" cell coordinates
+type MapData struct {
+	Cells map[string]*Cell `json:"cells"`
+}
+
+// Cell aggregates observations inside one cell
+type Cell struct {
+	X    int                  `json:"x"`
+	Y    int                  `json:"y"`
+	Mobs map[string]*MobStats `json:"mobs"`
+}
+
+// MobStats holds observations of one mob name in a cell
+type MobStats struct {
+	Sightings      int       `json:"sightings"`
+	Deaths         int       `json:"deaths"`
+	LastSeen       time.Time `json:"lastSeen"`
+	LastDeath      time.Time `json:"lastDeath"`
+	AwaitRespawn   bool      `json:"awaitRespawn"`
+	RespawnTotal   float64   `json:"respawnTotal"` // seconds, summed over samples
+	RespawnSamples int       `json:"respawnSamples"`
+}
+
+// Spot is a cell where a mob's respawn is due
+type Spot struct {
+	X, Y    float64 // cell center
+	Mob     string
+	Overdue time.Duration
+}
+
+// Load reads a heatmap from disk; a missing file gives an empty heatmap
+func Load(path string) (*Heatmap, error) {
+	h := &Heatmap{
+		path:    path,
+		Maps:    make(map[string]*MapData),
+		prev:    make(map[string]*game.Mob),
+		missing: make(map[string]int),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read heatmap: %w", err)
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse heatmap: %w", err)
+	}
+	if h.Maps == nil {
+		h.Maps = make(map[string]*MapData)
+	}
+
+	return h, nil
+}
+
+// Save writes the heatmap to disk
+func (h *Heatmap) Save() error {
+	h.mu.Lock()
+	data, err := json.Marshal(h)
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode heatmap: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create heatmap directory: %w", err)
+	}
+
+	// Write then rename so a crash never leaves a half-written file
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write heatmap: %w", err)
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// Observe records a mob snapshot. Mobs appearing count as sightings (and
+// finish a respawn timer in their cell), mobs that vanish or die for
+// deathPolls observations count as deaths. An empty snapshot is skipped:
+// the mobs script returns one when it fails, which would kill every mob.
+func (h *Heatmap) Observe(mapID string, mobs []*game.Mob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if mapID != h.lastMap {
+		// Mobs of the old map didn't die, we just left
+		h.lastMap = mapID
+		h.prev = make(map[string]*game.Mob)
+		h.missing = make(map[string]int)
+	}
+	if len(mobs) == 0 {
+		return
+	}
+
+	current := make(map[string]*game.Mob, len(mobs))
+	for _, m := range mobs {
+		if !m.Alive {
+			continue
+		}
+		current[m.ID] = m
+
+		stats := h.stats(mapID, m.X, m.Y, m.Name)
+		stats.LastSeen = now
+		if _, known := h.prev[m.ID]; known {
+			continue
+		}
+
+		stats.Sightings++
+		if stats.AwaitRespawn {
+			stats.RespawnTotal += now.Sub(stats.LastDeath).Seconds()
+			stats.RespawnSamples++
+			stats.AwaitRespawn = false
+		}
+	}
+
+	for id, m := range h.prev {
+		if _, alive := current[id]; alive {
+			delete(h.missing, id)
+			continue
+		}
+		h.missing[id]++
+		if h.missing[id] < deathPolls {
+			// Keep tracking it, it may be back in the next poll
+			current[id] = m
+			continue
+		}
+		delete(h.missing, id)
+
+		stats := h.stats(mapID, m.X, m.Y, m.Name)
+		stats.Deaths++
+		stats.LastDeath = now
+		stats.AwaitRespawn = true
+	}
+
+	h.prev = current
+}
+
+// RespawnEstimate returns the average respawn time of a mob on a map
+func (h *Heatmap) RespawnEstimate(mapID, mobName string) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m, ok := h.Maps[mapID]
+	if !ok {
+		return 0, false
+	}
+
+	total, samples := 0.0, 0
+	for _, c := range m.Cells {
+		if s, ok := c.Mobs[mobName]; ok {
+			total += s.RespawnTotal
+			samples += s.RespawnSamples
+		}
+	}
+	if samples == 0 {
+		return 0, false
+	}
+
+	return time.Duration(total / float64(samples) * float64(time.Second)), true
+}
+
+// DueSpots returns cells where a killed mob should have respawned by now,
+// most overdue first
+func (h *Heatmap) DueSpots(mapID string) []Spot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m, ok := h.Maps[mapID]
+	if !ok {
+		return nil
+	}
+
+	spots := make([]Spot, 0)
+	for _, c := range m.Cells {
+		for name, s := range c.Mobs {
+			if !s.AwaitRespawn || s.RespawnSamples == 0 {
+				continue
+			}
+			respawn := time.Duration(s.RespawnTotal / float64(s.RespawnSamples) * float64(time.Second))
+			overdue := time.Since(s.LastDeath) - respawn
+			if overdue < 0 {
+				continue
+			}
+			spots = append(spots, Spot{
+				X:       (float64(c.X) + 0.5) * CellSize,
+				Y:       (float64(c.Y) + 0.5) * CellSize,
+				Mob:     name,
+				Overdue: overdue,
+			})
+		}
+	}
+
+	sort.Slice(spots, func(i, j int) bool {
+		return spots[i].Overdue > spots[j].Overdue
+	})
+	return spots
+}
+
+// MapIDs returns the maps with recorded observations
+func (h *Heatmap) MapIDs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := make([]string, 0, len(h.Maps))
+	for id := range h.Maps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// MobNames returns the mob names seen on a map
+func (h *Heatmap) MobNames(mapID string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := make(map[string]bool)
+	if m, ok := h.Maps[mapID]; ok {
+		for _, c := range m.Cells {
+			for name := range c.Mobs {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stats returns the stats of a mob name in the cell containing x/y, creating them as needed
+func (h *Heatmap) stats(mapID string, x, y float64, name string) *MobStats {
+	m, ok := h.Maps[mapID]
+	if !ok {
+		m = &MapData{Cells: make(map[string]*Cell)}
+		h.Maps[mapID] = m
+	}
+
+	cx := int(math.Floor(x / CellSize))
+	cy := int(math.Floor(y / CellSize))
+	key := fmt.Sprintf("%d,%d", cx, cy)
+
+	c, ok := m.Cells[key]
+	if !ok {
+		c = &Cell{X: cx, Y: cy, Mobs: make(map[string]*MobStats)}
+		m.Cells[key] = c
+	}
+
+	s, ok := c.Mobs[name]
+	if !ok {
+		s = &MobStats{}
+		c.Mobs[name] = s
+	}
+	return s
+}
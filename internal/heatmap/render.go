@@ -0,0 +1,75 @@
+package heatmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// pixelsPerCell is the size of one cell in the rendered image
+const pixelsPerCell = 8
+
+// Render draws a map's heatmap as a PNG. Cell brightness follows the
+// number of sightings; red marks cells with deaths, blue cells with only sightings.
+func (h *Heatmap) Render(mapID string, w io.Writer) error {
+	h.mu.Lock()
+	m, ok := h.Maps[mapID]
+	if !ok || len(m.Cells) == 0 {
+		h.mu.Unlock()
+		return fmt.Errorf("no observations for map %s", mapID)
+	}
+
+	minX, minY := math.MaxInt32, math.MaxInt32
+	maxX, maxY := math.MinInt32, math.MinInt32
+	maxCount := 0
+	type heat struct{ sightings, deaths int }
+	cells := make(map[[2]int]heat, len(m.Cells))
+	for _, c := range m.Cells {
+		var hc heat
+		for _, s := range c.Mobs {
+			hc.sightings += s.Sightings
+			hc.deaths += s.Deaths
+		}
+		cells[[2]int{c.X, c.Y}] = hc
+
+		minX, minY = min(minX, c.X), min(minY, c.Y)
+		maxX, maxY = max(maxX, c.X), max(maxY, c.Y)
+		maxCount = max(maxCount, hc.sightings+hc.deaths)
+	}
+	h.mu.Unlock()
+
+	width := (maxX - minX + 1) * pixelsPerCell
+	height := (maxY - minY + 1) * pixelsPerCell
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	background := color.RGBA{R: 20, G: 20, B: 20, A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, background)
+		}
+	}
+
+	for pos, hc := range cells {
+		// Square root keeps a few hot cells from washing out the rest
+		intensity := math.Sqrt(float64(hc.sightings+hc.deaths) / float64(maxCount))
+		level := uint8(60 + intensity*195)
+
+		c := color.RGBA{R: 0, G: level / 3, B: level, A: 255}
+		if hc.deaths > 0 {
+			c = color.RGBA{R: level, G: level / 3, B: 0, A: 255}
+		}
+
+		x0 := (pos[0] - minX) * pixelsPerCell
+		y0 := (pos[1] - minY) * pixelsPerCell
+		for y := y0; y < y0+pixelsPerCell; y++ {
+			for x := x0; x < x0+pixelsPerCell; x++ {
+				img.Set(x, y, c)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,110 @@
+package loot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+// Collector picks up drops after a kill
+type Collector struct {
+	gameClient *game.Client
+	cfg        *config.Config
+	log        *logrus.Logger
+	filter     *Filter
+}
+
+// NewCollector creates a new loot collector
+func NewCollector(gameClient *game.Client, cfg *config.Config, log *logrus.Logger) *Collector {
+	return &Collector{
+		gameClient: gameClient,
+		cfg:        cfg,
+		log:        log,
+		filter:     NewFilter(cfg.Loot.ItemFilter),
+	}
+}
+
+// Collect waits briefly for the loot window, takes the items accepted by
+// the filter and declines the rest
+func (c *Collector) Collect(stateMgr *game.StateManager) error {
+	if !c.cfg.Loot.Enabled {
+		return nil
+	}
+
+	items, err := c.waitForLoot(3 * time.Second)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		c.log.Debug("No loot found")
+		return nil
+	}
+
+	var take, decline []string
+	var picked, fromWindow []*game.Item // ground items picked up, window items to take
+	for _, item := range items {
+		ok, reason := c.filter.Matches(item)
+
+		fields := logrus.Fields{
+			"item":   item.Name,
+			"rarity": item.Rarity,
+			"value":  item.Value,
+			"reason": reason,
+		}
+		if !ok {
+			c.log.WithFields(fields).Debug("Declining loot")
+			if item.Source == "window" {
+				decline = append(decline, item.ID)
+			}
+			continue
+		}
+
+		c.log.WithFields(fields).Info("Taking loot")
+		if item.Source == "window" {
+			take = append(take, item.ID)
+			fromWindow = append(fromWindow, item)
+			continue
+		}
+
+		behavior.RandomPause()
+		if err := c.gameClient.PickUpItem(item.ID); err != nil {
+			c.log.WithError(err).WithField("item", item.Name).Warn("Failed to pick up item")
+			continue
+		}
+		picked = append(picked, item)
+	}
+
+	if len(take) > 0 || len(decline) > 0 {
+		behavior.RandomPause()
+		if err := c.gameClient.ResolveLoot(take, decline); err != nil {
+			// The ground items are in the bags all the same
+			stateMgr.AddItems(picked)
+			stateMgr.RecordLoot(len(picked))
+			return fmt.Errorf("failed to resolve loot: %w", err)
+		}
+	}
+
+	taken := append(picked, fromWindow...)
+	stateMgr.AddItems(taken)
+	stateMgr.RecordLoot(len(taken))
+	return nil
+}
+
+// waitForLoot polls until loot shows up or the timeout passes
+func (c *Collector) waitForLoot(timeout time.Duration) ([]*game.Item, error) {
+	start := time.Now()
+	for {
+		items, err := c.gameClient.GetLoot()
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 0 || time.Since(start) >= timeout {
+			return items, nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package loot
+
+import (
+	"strings"
+
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+)
+
+// Filter decides whether an item matches a set of item rules
+type Filter struct {
+	whitelist []string
+	blacklist []string
+	rarities  map[string]bool
+	types     map[string]bool
+	minValue  int
+	maxValue  int
+}
+
+// NewFilter creates a filter from item rules
+func NewFilter(rules config.ItemFilter) *Filter {
+	return &Filter{
+		whitelist: lowerAll(rules.Whitelist),
+		blacklist: lowerAll(rules.Blacklist),
+		rarities:  toSet(rules.Rarities),
+		types:     toSet(rules.Types),
+		minValue:  rules.MinValue,
+		maxValue:  rules.MaxValue,
+	}
+}
+
+// Matches checks an item against the rules and returns the reason for the decision
+func (f *Filter) Matches(item *game.Item) (bool, string) {
+	name := strings.ToLower(item.Name)
+
+	if containsAny(name, f.blacklist) {
+		return false, "blacklisted"
+	}
+	if containsAny(name, f.whitelist) {
+		return true, "whitelisted"
+	}
+
+	if len(f.rarities) > 0 && !f.rarities[strings.ToLower(item.Rarity)] {
+		return false, "rarity " + item.Rarity
+	}
+	if len(f.types) > 0 && !f.types[strings.ToLower(item.Type)] {
+		return false, "type " + item.Type
+	}
+	if item.Value < f.minValue {
+		return false, "value too low"
+	}
+	if f.maxValue > 0 && item.Value > f.maxValue {
+		return false, "value too high"
+	}
+
+	return true, "rules"
+}
+
+// Empty reports whether the filter has no rules at all and so matches every item
+func (f *Filter) Empty() bool {
+	return len(f.whitelist) == 0 && len(f.blacklist) == 0 &&
+		len(f.rarities) == 0 && len(f.types) == 0 &&
+		f.minValue == 0 && f.maxValue == 0
+}
+
+func containsAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(list []string) []string {
+	result := make([]string, len(list))
+	for i, s := range list {
+		result[i] = strings.ToLower(s)
+	}
+	return result
+}
+
+func toSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[strings.ToLower(s)] = true
+	}
+	return set
+}
@@ -0,0 +1,178 @@
+package navigation
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+)
+
+// ShapeContains checks if a point lies inside a shape
+func ShapeContains(s config.Shape, p behavior.Point) bool {
+	if len(s.Polygon) >= 3 {
+		return pointInPolygon(s.Polygon, p)
+	}
+	return behavior.Distance(behavior.Point{X: s.X, Y: s.Y}, p) <= s.Radius
+}
+
+// ShapeCenter returns the center of a shape (the vertex average for polygons)
+func ShapeCenter(s config.Shape) behavior.Point {
+	if len(s.Polygon) == 0 {
+		return behavior.Point{X: s.X, Y: s.Y}
+	}
+
+	var center behavior.Point
+	for _, v := range s.Polygon {
+		center.X += v.X
+		center.Y += v.Y
+	}
+	center.X /= float64(len(s.Polygon))
+	center.Y /= float64(len(s.Polygon))
+	return center
+}
+
+// NearestPointInShape returns the closest point of a shape to p
+func NearestPointInShape(s config.Shape, p behavior.Point) behavior.Point {
+	if ShapeContains(s, p) {
+		return p
+	}
+
+	if len(s.Polygon) < 3 {
+		center := behavior.Point{X: s.X, Y: s.Y}
+		dist := behavior.Distance(center, p)
+		if dist == 0 || s.Radius == 0 {
+			return center
+		}
+		return behavior.Point{
+			X: center.X + (p.X-center.X)/dist*s.Radius,
+			Y: center.Y + (p.Y-center.Y)/dist*s.Radius,
+		}
+	}
+
+	// Closest point on any polygon edge
+	best := behavior.Point{X: s.Polygon[0].X, Y: s.Polygon[0].Y}
+	bestDist := math.MaxFloat64
+	for i := range s.Polygon {
+		a := s.Polygon[i]
+		b := s.Polygon[(i+1)%len(s.Polygon)]
+		q := closestOnSegment(
+			behavior.Point{X: a.X, Y: a.Y},
+			behavior.Point{X: b.X, Y: b.Y},
+			p,
+		)
+		if d := behavior.Distance(q, p); d < bestDist {
+			best = q
+			bestDist = d
+		}
+	}
+	return best
+}
+
+// pointInPolygon uses ray casting to test polygon membership
+func pointInPolygon(poly []config.Point, p behavior.Point) bool {
+	inside := false
+	j := len(poly) - 1
+	for i := 0; i < len(poly); i++ {
+		a, b := poly[i], poly[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) &&
+			p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
+
+// closestOnSegment projects p onto the segment a-b
+func closestOnSegment(a, b, p behavior.Point) behavior.Point {
+	dx := b.X - a.X
+	dy := b.Y - a.Y
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return a
+	}
+
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / lenSq
+	t = math.Max(0, math.Min(1, t))
+	return behavior.Point{X: a.X + t*dx, Y: a.Y + t*dy}
+}
+
+// GroundContains checks if a point lies inside a hunting ground: within one
+// of its areas (or its circle when no areas are set) and outside every exclusion
+func GroundContains(g config.HuntingGround, p behavior.Point) bool {
+	inside := false
+	if len(g.Areas) == 0 {
+		inside = behavior.Distance(behavior.Point{X: g.CenterX, Y: g.CenterY}, p) <= g.Radius
+	}
+	for _, a := range g.Areas {
+		if ShapeContains(a, p) {
+			inside = true
+			break
+		}
+	}
+	if !inside {
+		return false
+	}
+
+	for _, ex := range g.Exclude {
+		if ShapeContains(ex, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// GroundCenter returns the point to head for when walking to a hunting
+// ground: its center, or the center of its first area
+func GroundCenter(g config.HuntingGround) behavior.Point {
+	if len(g.Areas) == 0 {
+		return behavior.Point{X: g.CenterX, Y: g.CenterY}
+	}
+	return ShapeCenter(g.Areas[0])
+}
+
+// RandomPointInGround samples a random point inside a hunting ground,
+// keeping margin (a fraction of the size, e.g. 0.2) away from circle edges
+func RandomPointInGround(g config.HuntingGround, margin float64) (behavior.Point, bool) {
+	areas := g.Areas
+	if len(areas) == 0 {
+		areas = []config.Shape{{X: g.CenterX, Y: g.CenterY, Radius: g.Radius}}
+	}
+
+	// Rejection sampling inside the bounding box of a random area
+	for try := 0; try < 50; try++ {
+		area := areas[rand.Intn(len(areas))]
+
+		var p behavior.Point
+		if len(area.Polygon) >= 3 {
+			minX, minY, maxX, maxY := polygonBounds(area.Polygon)
+			p = behavior.Point{
+				X: minX + rand.Float64()*(maxX-minX),
+				Y: minY + rand.Float64()*(maxY-minY),
+			}
+		} else {
+			offset := behavior.RandomOffset(area.Radius * (1 - margin))
+			p = behavior.Point{X: area.X + offset.X, Y: area.Y + offset.Y}
+		}
+
+		if GroundContains(g, p) {
+			return p, true
+		}
+	}
+
+	return behavior.Point{}, false
+}
+
+// polygonBounds returns the bounding box of a polygon
+func polygonBounds(poly []config.Point) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.MaxFloat64, math.MaxFloat64
+	maxX, maxY = -math.MaxFloat64, -math.MaxFloat64
+	for _, v := range poly {
+		minX = math.Min(minX, v.X)
+		minY = math.Min(minY, v.Y)
+		maxX = math.Max(maxX, v.X)
+		maxY = math.Max(maxY, v.Y)
+	}
+	return minX, minY, maxX, maxY
+}
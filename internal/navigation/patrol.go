@@ -0,0 +1,176 @@
+package navigation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/heatmap"
+)
+
+const (
+	spawnRadius        = 80.0            // a mob this close to a patrol point was seen at it
+	defaultSpawnMemory = 5 * time.Minute // how long a sighting counts as recent by default
+)
+
+// patrolState remembers where the patrol is along the configured points
+type patrolState struct {
+	ground      config.HuntingGround
+	groundIndex int // the ground's index in grounds()
+	index       int
+	direction   int
+	lastSeen    map[string]time.Time // point name -> last live mob sighting
+	lastVisited map[string]time.Time
+}
+
+// ObserveSpawns records which patrol points have live mobs around them
+func (n *Navigator) ObserveSpawns(stateMgr *game.StateManager) {
+	patrol := n.patrolState()
+	hero := stateMgr.GetHero()
+	if hero.MapID != patrol.ground.MapID {
+		return
+	}
+
+	now := time.Now()
+	for _, m := range stateMgr.GetMobs() {
+		if !m.Alive {
+			continue
+		}
+		for _, p := range patrol.ground.Patrol.Points {
+			if behavior.Distance(behavior.Point{X: m.X, Y: m.Y}, behavior.Point{X: p.X, Y: p.Y}) <= spawnRadius {
+				patrol.lastSeen[p.Name] = now
+			}
+		}
+	}
+}
+
+// nextPatrolPoint picks where to patrol to next according to the patrol mode
+func (n *Navigator) nextPatrolPoint(hero *game.HeroState) (behavior.Point, error) {
+	patrol := n.patrolState()
+	cfg := patrol.ground.Patrol
+	points := cfg.Points
+
+	// Head for learned spawns whose respawn is due, unless the route is fixed
+	if cfg.Mode != "loop" && cfg.Mode != "pingpong" {
+		if spot, ok := n.dueSpot(hero, patrol); ok {
+			return spot, nil
+		}
+	}
+
+	var next config.PatrolPoint
+	switch cfg.Mode {
+	case "loop":
+		patrol.index = (patrol.index + 1) % len(points)
+		next = points[patrol.index]
+
+	case "pingpong":
+		if patrol.index+patrol.direction < 0 || patrol.index+patrol.direction >= len(points) {
+			patrol.direction = -patrol.direction
+		}
+		patrol.index += patrol.direction
+		next = points[patrol.index]
+
+	case "spawns":
+		next = patrol.pickSpawn(hero, cfg)
+
+	default:
+		p, ok := RandomPointInGround(patrol.ground, 0.2) // Stay within 80% of radius
+		if !ok {
+			return behavior.Point{}, fmt.Errorf("no free spot found in hunting ground")
+		}
+		return p, nil
+	}
+
+	patrol.lastVisited[next.Name] = time.Now()
+	return behavior.AddJitter(behavior.Point{X: next.X, Y: next.Y}, n.cfg.Behavior.PathJitter), nil
+}
+
+// dueSpot returns the most overdue learned spawn on the hunting ground
+// that wasn't visited recently
+func (n *Navigator) dueSpot(hero *game.HeroState, patrol *patrolState) (behavior.Point, bool) {
+	if n.heatmap == nil || hero.MapID != patrol.ground.MapID {
+		return behavior.Point{}, false
+	}
+
+	memory := time.Duration(patrol.ground.Patrol.SpawnMemory) * time.Second
+	if memory == 0 {
+		memory = defaultSpawnMemory
+	}
+	here := behavior.Point{X: hero.X, Y: hero.Y}
+
+	for _, spot := range n.heatmap.DueSpots(hero.MapID) {
+		p := behavior.Point{X: spot.X, Y: spot.Y}
+		key := fmt.Sprintf("spot:%.0f,%.0f", spot.X, spot.Y)
+		if !GroundContains(patrol.ground, p) || behavior.Distance(here, p) <= spawnRadius {
+			continue
+		}
+		if time.Since(patrol.lastVisited[key]) <= memory {
+			continue
+		}
+
+		n.log.WithField("mob", spot.Mob).Debug("Patrolling to due respawn")
+		patrol.lastVisited[key] = time.Now()
+		return p, true
+	}
+
+	return behavior.Point{}, false
+}
+
+// UseHeatmap lets patrols head for learned spawns whose respawn is due
+func (n *Navigator) UseHeatmap(h *heatmap.Heatmap) {
+	n.heatmap = h
+}
+
+// pickSpawn prefers the point where mobs were seen most recently; when no
+// sighting is recent enough it goes to the point visited longest ago
+func (p *patrolState) pickSpawn(hero *game.HeroState, cfg config.PatrolConfig) config.PatrolPoint {
+	memory := time.Duration(cfg.SpawnMemory) * time.Second
+	if memory == 0 {
+		memory = defaultSpawnMemory
+	}
+	here := behavior.Point{X: hero.X, Y: hero.Y}
+
+	var best, oldest *config.PatrolPoint
+	for i := range cfg.Points {
+		pt := &cfg.Points[i]
+		// No point walking to where we already stand
+		if behavior.Distance(here, behavior.Point{X: pt.X, Y: pt.Y}) <= spawnRadius {
+			continue
+		}
+
+		if seen, ok := p.lastSeen[pt.Name]; ok && time.Since(seen) <= memory {
+			if best == nil || seen.After(p.lastSeen[best.Name]) {
+				best = pt
+			}
+		}
+		if oldest == nil || p.lastVisited[pt.Name].Before(p.lastVisited[oldest.Name]) {
+			oldest = pt
+		}
+	}
+
+	if best != nil {
+		return *best
+	}
+	if oldest != nil {
+		return *oldest
+	}
+	return cfg.Points[0]
+}
+
+// patrolState returns the patrol state, starting over when the hunting ground changed
+func (n *Navigator) patrolState() *patrolState {
+	ground := n.cfg.Profile.HuntingGround
+	if n.patrol == nil || n.patrol.groundIndex != n.current {
+		n.patrol = &patrolState{
+			groundIndex: n.current,
+			index:       -1,
+			direction:   1,
+			lastSeen:    make(map[string]time.Time),
+			lastVisited: make(map[string]time.Time),
+		}
+	}
+	n.patrol.ground = ground
+	return n.patrol
+}
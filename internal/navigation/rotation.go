@@ -0,0 +1,224 @@
+package navigation
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sparseGrace    = 1 * time.Minute // how long mob density must stay low before leaving
+	rotateCooldown = 5 * time.Minute // least time on a ground before its leave rules apply, at most maxMinutes
+)
+
+// ShouldRotate checks the current hunting ground's leave rules
+func (n *Navigator) ShouldRotate(stateMgr *game.StateManager) (bool, string) {
+	if len(n.grounds()) < 2 {
+		return false, ""
+	}
+
+	ground := n.cfg.Profile.HuntingGround
+	rules := ground.Leave
+	hero := stateMgr.GetHero()
+	if hero.MapID != ground.MapID {
+		return false, ""
+	}
+
+	if ground.MaxHeroLevel > 0 && hero.Level > ground.MaxHeroLevel && n.hasGroundFor(hero.Level) {
+		return true, fmt.Sprintf("hero level %d outgrew the ground (max %d)", hero.Level, ground.MaxHeroLevel)
+	}
+
+	if n.arrivedAt.IsZero() {
+		n.arrivedAt = time.Now()
+	}
+	// Keep from hopping back and forth between grounds, unless the ground
+	// is only meant for a shorter stay
+	maxStay := time.Duration(rules.MaxMinutes) * time.Minute
+	cooldown := rotateCooldown
+	if rules.MaxMinutes > 0 && maxStay < cooldown {
+		cooldown = maxStay
+	}
+	if time.Since(n.arrivedAt) < cooldown {
+		return false, ""
+	}
+	if rules.MaxMinutes > 0 && time.Since(n.arrivedAt) > maxStay {
+		return true, fmt.Sprintf("spent %d minutes here", rules.MaxMinutes)
+	}
+
+	if rules.PlayerNearby {
+		for _, p := range stateMgr.GetPlayers() {
+			if onGround(ground, p.X, p.Y) && !n.cfg.Players.Ignores(p.Nick) {
+				return true, "player " + p.Nick + " on the hunting ground"
+			}
+		}
+	}
+
+	if rules.MinMobs > 0 {
+		alive := 0
+		for _, m := range stateMgr.GetMobs() {
+			if m.Alive && onGround(ground, m.X, m.Y) {
+				alive++
+			}
+		}
+
+		if alive >= rules.MinMobs {
+			n.sparseSince = time.Time{}
+		} else if n.sparseSince.IsZero() {
+			n.sparseSince = time.Now()
+		} else if time.Since(n.sparseSince) > sparseGrace {
+			return true, fmt.Sprintf("only %d mobs left", alive)
+		}
+	}
+
+	return false, ""
+}
+
+// SwitchHuntingGround picks another hunting ground by weight and travels
+// there, following its waypoints when it is on another map. The new ground
+// only replaces the current one once its map is reached.
+func (n *Navigator) SwitchHuntingGround(stateMgr *game.StateManager) error {
+	grounds := n.grounds()
+	if len(grounds) < 2 {
+		return fmt.Errorf("no alternate hunting grounds configured")
+	}
+
+	hero := stateMgr.GetHero()
+	next, err := pickGround(grounds, n.current, hero.Level)
+	if err != nil {
+		return err
+	}
+	ground := grounds[next]
+
+	n.log.WithFields(logrus.Fields{
+		"name": ground.Name,
+		"map":  ground.MapID,
+		"x":    ground.CenterX,
+		"y":    ground.CenterY,
+	}).Info("Switching hunting ground")
+
+	if hero.MapID != ground.MapID {
+		if err := n.FollowRoute(n.routeTo(next), stateMgr); err != nil {
+			return err
+		}
+
+		hero = stateMgr.GetHero()
+		if hero.MapID != ground.MapID {
+			return fmt.Errorf("hunting ground map %s not reached (on %s)", ground.MapID, hero.MapID)
+		}
+	}
+
+	n.cfg.Profile.HuntingGround = ground
+	n.current = next
+	n.arrivedAt = time.Now()
+	n.sparseSince = time.Time{}
+
+	current := behavior.Point{X: hero.X, Y: hero.Y}
+	for _, point := range behavior.GeneratePath(current, GroundCenter(ground), 50) {
+		if err := n.gameClient.MoveToAndWait(point.X, point.Y, game.ArriveTolerance, stepTimeout); err != nil {
+			return fmt.Errorf("failed to walk to hunting ground: %w", err)
+		}
+
+		behavior.SleepRange(
+			n.cfg.Behavior.GetMinDelay()/2,
+			n.cfg.Behavior.GetMaxDelay()/2,
+		)
+	}
+
+	n.arrivedAt = time.Now()
+	return nil
+}
+
+// grounds returns the primary hunting ground followed by the alternates.
+// Grounds are told apart by their index in this list, as area-only grounds
+// share no center to compare.
+func (n *Navigator) grounds() []config.HuntingGround {
+	// Remember the primary ground before the first switch overwrites it
+	if n.primary == nil {
+		primary := n.cfg.Profile.HuntingGround
+		n.primary = &primary
+	}
+	return append([]config.HuntingGround{*n.primary}, n.cfg.Profile.AlternateGrounds...)
+}
+
+// routeTo returns the waypoints leading to a hunting ground by index: the
+// profile route for the primary ground, the ground's own for the alternates
+func (n *Navigator) routeTo(index int) []config.Waypoint {
+	if index == 0 {
+		return n.cfg.Profile.Waypoints
+	}
+	return n.grounds()[index].Waypoints
+}
+
+// hasGroundFor reports whether another hunting ground suits the hero level
+func (n *Navigator) hasGroundFor(level int) bool {
+	for i, g := range n.grounds() {
+		if i != n.current && suitsLevel(g, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickGround chooses the index of a hunting ground other than current,
+// weighted by Weight. Grounds suiting the hero level are preferred when
+// there are any.
+func pickGround(grounds []config.HuntingGround, current, level int) (int, error) {
+	candidates := make([]int, 0, len(grounds))
+	for i, g := range grounds {
+		if i != current && suitsLevel(g, level) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range grounds {
+			if i != current {
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("no other hunting ground to switch to")
+	}
+
+	total := 0.0
+	for _, i := range candidates {
+		total += groundWeight(grounds[i])
+	}
+
+	r := rand.Float64() * total
+	for _, i := range candidates {
+		r -= groundWeight(grounds[i])
+		if r < 0 {
+			return i, nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// suitsLevel checks a hunting ground's hero level band; unset bounds always match
+func suitsLevel(g config.HuntingGround, level int) bool {
+	if g.MinHeroLevel > 0 && level < g.MinHeroLevel {
+		return false
+	}
+	if g.MaxHeroLevel > 0 && level > g.MaxHeroLevel {
+		return false
+	}
+	return true
+}
+
+func groundWeight(g config.HuntingGround) float64 {
+	if g.Weight == 0 {
+		return 1
+	}
+	return g.Weight
+}
+
+// onGround checks if a position lies within a hunting ground
+func onGround(g config.HuntingGround, x, y float64) bool {
+	return GroundContains(g, behavior.Point{X: x, Y: y})
+}
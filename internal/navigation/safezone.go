@@ -0,0 +1,88 @@
+package navigation
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+)
+
+// NearestSafeZone finds the closest safe zone on the given map and the point
+// inside it to walk to. Zones on other maps are skipped.
+func NearestSafeZone(zones []config.SafeZone, mapID string, from behavior.Point) (*config.SafeZone, behavior.Point, bool) {
+	var nearest *config.SafeZone
+	var target behavior.Point
+	minDist := math.MaxFloat64
+
+	for i := range zones {
+		zone := &zones[i]
+		if zone.MapID != mapID {
+			continue
+		}
+
+		p := NearestPointInShape(zone.Shape, from)
+		if dist := behavior.Distance(from, p); dist < minDist {
+			minDist = dist
+			nearest = zone
+			target = p
+		}
+	}
+
+	return nearest, target, nearest != nil
+}
+
+// FleePoint returns a point the given distance away from the centroid of threats
+func FleePoint(from behavior.Point, threats []behavior.Point, dist float64) behavior.Point {
+	if len(threats) == 0 {
+		offset := behavior.RandomOffset(dist)
+		return behavior.Point{X: from.X + offset.X, Y: from.Y + offset.Y}
+	}
+
+	var centroid behavior.Point
+	for _, t := range threats {
+		centroid.X += t.X
+		centroid.Y += t.Y
+	}
+	centroid.X /= float64(len(threats))
+	centroid.Y /= float64(len(threats))
+
+	dx := from.X - centroid.X
+	dy := from.Y - centroid.Y
+	length := math.Sqrt(dx*dx + dy*dy)
+	if length == 0 {
+		// Standing on top of the pack, any direction is as good as another
+		angle := rand.Float64() * 2 * math.Pi
+		dx, dy, length = math.Cos(angle), math.Sin(angle), 1
+	}
+
+	return behavior.Point{
+		X: from.X + dx/length*dist,
+		Y: from.Y + dy/length*dist,
+	}
+}
+
+// EscapePath builds a stepped path from -> to where every step is pushed
+// out of the clearance radius around each threat
+func EscapePath(from, to behavior.Point, threats []behavior.Point, stepSize, clearance float64) []behavior.Point {
+	path := behavior.GeneratePath(from, to, stepSize)
+
+	// Leave the destination untouched so we still arrive where we wanted
+	for i := 0; i < len(path)-1; i++ {
+		for _, t := range threats {
+			dist := behavior.Distance(path[i], t)
+			if dist >= clearance {
+				continue
+			}
+			if dist == 0 {
+				dist = 1
+			}
+
+			push := clearance - dist
+			path[i].X += (path[i].X - t.X) / dist * push
+			path[i].Y += (path[i].Y - t.Y) / dist * push
+		}
+	}
+
+	return path
+}
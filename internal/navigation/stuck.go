@@ -0,0 +1,215 @@
+package navigation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/browser"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/events"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sidestepDistance = 64.0            // how far to step aside when re-pathing fails
+	ladderReset      = 2 * time.Minute // start over from the first step after this long unstuck
+)
+
+// Escalation steps, from cheapest to most drastic
+const (
+	StepRepath    = "repath"
+	StepSidestep  = "sidestep"
+	StepLastGood  = "last_good_point"
+	StepReload    = "reload"
+	StepTeleport  = "teleport"
+	stepsInLadder = 5
+)
+
+var ladder = [stepsInLadder]string{StepRepath, StepSidestep, StepLastGood, StepReload, StepTeleport}
+
+// StuckEvent is published on the bus for every recovery attempt
+type StuckEvent struct {
+	Step    string
+	Attempt int // escalation level, 1 for the first step
+	X, Y    float64
+	MapID   string
+	Err     error
+}
+
+// Unsticker gets the hero moving again, escalating one step each time it
+// is called while the hero stays stuck
+type Unsticker struct {
+	browser     *browser.Controller
+	gameClient  *game.Client
+	navigator   *Navigator
+	bus         *events.Bus
+	cfg         *config.Config
+	log         *logrus.Logger
+	step        int
+	lastAttempt time.Time
+}
+
+// NewUnsticker creates a new stuck recovery ladder
+func NewUnsticker(browser *browser.Controller, gameClient *game.Client, navigator *Navigator, bus *events.Bus, cfg *config.Config, log *logrus.Logger) *Unsticker {
+	return &Unsticker{
+		browser:    browser,
+		gameClient: gameClient,
+		navigator:  navigator,
+		bus:        bus,
+		cfg:        cfg,
+		log:        log,
+	}
+}
+
+// Unstick runs the next escalation step
+func (u *Unsticker) Unstick(stateMgr *game.StateManager) error {
+	if time.Since(u.lastAttempt) > ladderReset {
+		u.step = 0
+	}
+	u.lastAttempt = time.Now()
+
+	step := ladder[u.step]
+	hero := stateMgr.GetHero()
+	u.log.WithFields(logrus.Fields{
+		"step":    step,
+		"attempt": u.step + 1,
+		"x":       hero.X,
+		"y":       hero.Y,
+	}).Warn("Hero stuck, escalating")
+
+	var err error
+	switch step {
+	case StepRepath:
+		err = u.repath(stateMgr)
+	case StepSidestep:
+		err = u.sidestep(stateMgr)
+	case StepLastGood:
+		err = u.lastGoodPoint(stateMgr)
+	case StepReload:
+		err = u.reload(stateMgr)
+	case StepTeleport:
+		err = u.teleport(stateMgr)
+	}
+
+	stateMgr.RecordUnstick(step)
+	u.bus.Publish(events.Stuck, StuckEvent{
+		Step:    step,
+		Attempt: u.step + 1,
+		X:       hero.X,
+		Y:       hero.Y,
+		MapID:   hero.MapID,
+		Err:     err,
+	})
+
+	// Back to the bottom of the ladder once something worked, otherwise
+	// the next detection escalates (wrapping around after the last step)
+	if err == nil && step != StepReload {
+		u.step = 0
+	} else {
+		u.step = (u.step + 1) % stepsInLadder
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", step, err)
+	}
+	return nil
+}
+
+// repath walks to the goal again along a freshly jittered path
+func (u *Unsticker) repath(stateMgr *game.StateManager) error {
+	goal, ok := stateMgr.GetMoveGoal()
+	if !ok {
+		return nil
+	}
+
+	hero := stateMgr.GetHero()
+	from := behavior.Point{X: hero.X, Y: hero.Y}
+	for _, p := range behavior.GeneratePath(from, behavior.Point{X: goal.X, Y: goal.Y}, 50) {
+		p = behavior.AddJitter(p, u.cfg.Behavior.PathJitter*3)
+		if err := u.gameClient.MoveToAndWait(p.X, p.Y, game.ArriveTolerance, stepTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sidestep steps perpendicular to the goal direction, then heads for the goal
+func (u *Unsticker) sidestep(stateMgr *game.StateManager) error {
+	goal, ok := stateMgr.GetMoveGoal()
+	if !ok {
+		return nil
+	}
+
+	hero := stateMgr.GetHero()
+	dx, dy := goal.X-hero.X, goal.Y-hero.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return nil
+	}
+
+	side := 1.0
+	if rand.Intn(2) == 0 {
+		side = -1
+	}
+	aside := behavior.Point{
+		X: hero.X - dy/length*sidestepDistance*side,
+		Y: hero.Y + dx/length*sidestepDistance*side,
+	}
+
+	if err := u.gameClient.MoveToAndWait(aside.X, aside.Y, game.ArriveTolerance, stepTimeout); err != nil {
+		return err
+	}
+	return u.gameClient.MoveToAndWait(goal.X, goal.Y, game.ArriveTolerance, waypointTimeout)
+}
+
+// lastGoodPoint walks back to the last place the hero reached, falling
+// back to the hunting ground center
+func (u *Unsticker) lastGoodPoint(stateMgr *game.StateManager) error {
+	target := GroundCenter(u.cfg.Profile.HuntingGround)
+	if p, ok := stateMgr.LastGoodPoint(); ok {
+		target = behavior.Point{X: p.X, Y: p.Y}
+	}
+
+	stateMgr.ClearMoveGoal()
+	return u.gameClient.MoveToAndWait(target.X, target.Y, game.ArriveTolerance, waypointTimeout)
+}
+
+// reload reloads the game page
+func (u *Unsticker) reload(stateMgr *game.StateManager) error {
+	stateMgr.ClearMoveGoal()
+	if err := u.browser.Navigate(u.cfg.Account.StartURL); err != nil {
+		return err
+	}
+	return u.gameClient.EnsureReady()
+}
+
+// teleport reads a town teleport scroll and walks back to the hunting ground
+func (u *Unsticker) teleport(stateMgr *game.StateManager) error {
+	stateMgr.ClearMoveGoal()
+
+	key := u.cfg.Profile.Town.TeleportKey
+	if key == "" {
+		return fmt.Errorf("no teleport key configured")
+	}
+
+	mapID := stateMgr.GetHero().MapID
+	if err := u.gameClient.UsePotion(key); err != nil {
+		return err
+	}
+
+	// Wait for the map change
+	deadline := time.Now().Add(15 * time.Second)
+	for stateMgr.GetHero().MapID == mapID {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("teleport did not change the map")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	behavior.LongPause()
+	return u.navigator.GoToHuntingGround(stateMgr)
+}
@@ -1,20 +1,34 @@
 package navigation
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/kamilkurek/margonem-bot/internal/behavior"
 	"github.com/kamilkurek/margonem-bot/internal/config"
 	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/heatmap"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	waypointTimeout = 30 * time.Second // max walk time to a single waypoint
+	portalTimeout   = 10 * time.Second // max wait for a portal to change the map
+	stepTimeout     = 10 * time.Second // max walk time for one path step
+)
+
 // Navigator handles waypoint-based navigation
 type Navigator struct {
-	gameClient *game.Client
-	cfg        *config.Config
-	log        *logrus.Logger
+	gameClient  *game.Client
+	cfg         *config.Config
+	log         *logrus.Logger
+	primary     *config.HuntingGround
+	current     int // index of the hunting ground in grounds()
+	arrivedAt   time.Time
+	sparseSince time.Time
+	patrol      *patrolState
+	heatmap     *heatmap.Heatmap
 }
 
 // NewNavigator creates a new navigator
@@ -34,23 +48,27 @@ func (n *Navigator) GoToHuntingGround(stateMgr *game.StateManager) error {
 	target := n.cfg.Profile.HuntingGround
 	
 	// Check if already at hunting ground
-	if hero.MapID == target.MapID {
-		dist := behavior.Distance(
-			behavior.Point{X: hero.X, Y: hero.Y},
-			behavior.Point{X: target.CenterX, Y: target.CenterY},
-		)
-		
-		if dist <= target.Radius {
-			n.log.Info("Already at hunting ground")
-			return nil
-		}
+	if hero.MapID == target.MapID && GroundContains(target, behavior.Point{X: hero.X, Y: hero.Y}) {
+		n.log.Info("Already at hunting ground")
+		return nil
 	}
 	
 	// Follow waypoints
-	for i, wp := range n.cfg.Profile.Waypoints {
+	if err := n.FollowRoute(n.routeTo(n.current), stateMgr); err != nil {
+		return err
+	}
+	
+	n.log.Info("Arrived at hunting ground")
+	n.arrivedAt = time.Now()
+	return nil
+}
+
+// FollowRoute follows a list of waypoints in order
+func (n *Navigator) FollowRoute(route []config.Waypoint, stateMgr *game.StateManager) error {
+	for i, wp := range route {
 		n.log.WithFields(logrus.Fields{
 			"waypoint": i + 1,
-			"total":    len(n.cfg.Profile.Waypoints),
+			"total":    len(route),
 			"desc":     wp.Description,
 		}).Info("Following waypoint")
 		
@@ -65,7 +83,6 @@ func (n *Navigator) GoToHuntingGround(stateMgr *game.StateManager) error {
 		)
 	}
 	
-	n.log.Info("Arrived at hunting ground")
 	return nil
 }
 
@@ -74,32 +91,22 @@ func (n *Navigator) followWaypoint(wp config.Waypoint, stateMgr *game.StateManag
 	hero := stateMgr.GetHero()
 	
 	// Check if we need to change maps first
+	crossed := false
 	if hero.MapID != wp.MapID && wp.Action == "portal" {
 		n.log.WithField("map", wp.MapID).Debug("Using portal to change map")
 		
 		// Click portal if selector provided
 		if wp.Selector != "" {
-			// Try to click the portal selector
-			// This is a simplified version - might need more robust handling
-			n.log.WithField("selector", wp.Selector).Debug("Clicking portal")
-		}
-		
-		// Wait for map change
-		timeout := 10 * time.Second
-		start := time.Now()
-		
-		for time.Since(start) < timeout {
-			hero = stateMgr.GetHero()
-			if hero.MapID == wp.MapID {
-				n.log.Debug("Map changed successfully")
-				break
+			if err := n.clickPortal(wp); err != nil {
+				return err
 			}
-			time.Sleep(500 * time.Millisecond)
 		}
 		
-		if hero.MapID != wp.MapID {
+		// Wait for map change
+		if !n.waitForMap(stateMgr, func(mapID string) bool { return mapID == wp.MapID }) {
 			return fmt.Errorf("failed to change map after portal")
 		}
+		crossed = true
 	}
 	
 	// Move to waypoint coordinates with jitter
@@ -111,28 +118,79 @@ func (n *Navigator) followWaypoint(wp config.Waypoint, stateMgr *game.StateManag
 		"y": jitteredPos.Y,
 	}).Debug("Moving to waypoint")
 	
-	if err := n.gameClient.MoveTo(jitteredPos.X, jitteredPos.Y); err != nil {
+	// Walking onto a portal changes the map, which is fine on a route
+	err := n.gameClient.MoveToAndWait(jitteredPos.X, jitteredPos.Y, game.ArriveTolerance, waypointTimeout)
+	if errors.Is(err, game.ErrMapChanged) {
+		n.log.WithError(err).Debug("Map changed on the way to waypoint")
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("failed to move to waypoint: %w", err)
 	}
 	
-	// Wait for movement to complete
-	time.Sleep(2 * time.Second)
+	// Standing on the portal didn't take us through, click it
+	if wp.Action == "portal" && !crossed {
+		if err := n.clickPortal(wp); err != nil {
+			return err
+		}
+		if !n.waitForMap(stateMgr, func(mapID string) bool { return mapID != wp.MapID }) {
+			return fmt.Errorf("portal on %s did not change the map", wp.MapID)
+		}
+	}
 	
+	// Talk our way through (guards, ferrymen, teleporters)
+	if wp.Action == "dialog" {
+		n.log.WithField("npc", wp.NPCID).Debug("Running dialog script")
+		
+		if err := n.gameClient.RunDialog(wp.NPCID, wp.Dialog); err != nil {
+			return fmt.Errorf("dialog failed: %w", err)
+		}
+		
+		behavior.LongPause()
+	}
+	
+	return nil
+}
+
+// clickPortal clicks the waypoint's portal: its selector when set, the
+// portal's spot on the map otherwise
+func (n *Navigator) clickPortal(wp config.Waypoint) error {
+	var err error
+	if wp.Selector != "" {
+		n.log.WithField("selector", wp.Selector).Debug("Clicking portal")
+		err = n.gameClient.ClickElement(wp.Selector)
+	} else {
+		n.log.WithFields(logrus.Fields{
+			"x": wp.X,
+			"y": wp.Y,
+		}).Debug("Clicking portal")
+		err = n.gameClient.ClickWorld(wp.X, wp.Y)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to click portal: %w", err)
+	}
 	return nil
 }
 
-// PatrolArea moves around the hunting ground
+// waitForMap polls the hero until done accepts its map or portalTimeout passes
+func (n *Navigator) waitForMap(stateMgr *game.StateManager, done func(mapID string) bool) bool {
+	start := time.Now()
+	for time.Since(start) < portalTimeout {
+		if done(stateMgr.GetHero().MapID) {
+			n.log.Debug("Map changed successfully")
+			return true
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return false
+}
+
+// PatrolArea moves around the hunting ground following its patrol mode
 func (n *Navigator) PatrolArea(stateMgr *game.StateManager) error {
 	hero := stateMgr.GetHero()
-	huntingGround := n.cfg.Profile.HuntingGround
-	
-	// Generate a random position within the hunting ground
-	center := behavior.Point{X: huntingGround.CenterX, Y: huntingGround.CenterY}
-	offset := behavior.RandomOffset(huntingGround.Radius * 0.8) // Stay within 80% of radius
 	
-	targetPos := behavior.Point{
-		X: center.X + offset.X,
-		Y: center.Y + offset.Y,
+	targetPos, err := n.nextPatrolPoint(&hero)
+	if err != nil {
+		return err
 	}
 	
 	n.log.WithFields(logrus.Fields{
@@ -149,7 +207,7 @@ func (n *Navigator) PatrolArea(stateMgr *game.StateManager) error {
 		path := behavior.GeneratePath(currentPos, targetPos, 50)
 		
 		for _, point := range path {
-			if err := n.gameClient.MoveTo(point.X, point.Y); err != nil {
+			if err := n.gameClient.MoveToAndWait(point.X, point.Y, game.ArriveTolerance, stepTimeout); err != nil {
 				return fmt.Errorf("failed to patrol: %w", err)
 			}
 			
@@ -160,7 +218,7 @@ func (n *Navigator) PatrolArea(stateMgr *game.StateManager) error {
 		}
 	} else {
 		// Move directly
-		if err := n.gameClient.MoveTo(targetPos.X, targetPos.Y); err != nil {
+		if err := n.gameClient.MoveToAndWait(targetPos.X, targetPos.Y, game.ArriveTolerance, stepTimeout); err != nil {
 			return fmt.Errorf("failed to patrol: %w", err)
 		}
 	}
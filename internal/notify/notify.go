@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// Send shows a desktop notification on the local machine
+func Send(title, message string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", title, message)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		return fmt.Errorf("notifications not supported on %s", runtime.GOOS)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notification failed: %w (%s)", err, out)
+	}
+	return nil
+}
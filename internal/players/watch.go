@@ -0,0 +1,132 @@
+package players
+
+import (
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/navigation"
+	"github.com/sirupsen/logrus"
+)
+
+// switchCooldown keeps the bot from hopping between crowded grounds
+const switchCooldown = 5 * time.Minute
+
+// Watch applies the player policies: pausing when someone hangs around
+// and noticing when the hunting ground gets crowded
+type Watch struct {
+	cfg         *config.Config
+	log         *logrus.Logger
+	nearbySince map[string]time.Time
+	lastSwitch  time.Time
+}
+
+// NewWatch creates a new player watch
+func NewWatch(cfg *config.Config, log *logrus.Logger) *Watch {
+	return &Watch{
+		cfg:         cfg,
+		log:         log,
+		nearbySince: make(map[string]time.Time),
+	}
+}
+
+// Check pauses the bot when a player stays nearby for too long and
+// reports whether the hunting ground is crowded enough to move on
+func (w *Watch) Check(stateMgr *game.StateManager) bool {
+	policy := w.cfg.Players
+	hero := stateMgr.GetHero()
+	players := w.visible(stateMgr.GetPlayers())
+
+	// Track how long each player has been close to us
+	now := time.Now()
+	nearby := make(map[string]bool)
+	for _, p := range players {
+		if distance(hero.X, hero.Y, p.X, p.Y) > policy.NearbyRadius {
+			continue
+		}
+		nearby[p.Nick] = true
+		if _, ok := w.nearbySince[p.Nick]; !ok {
+			w.nearbySince[p.Nick] = now
+			w.log.WithFields(logrus.Fields{
+				"nick":  p.Nick,
+				"level": p.Level,
+				"clan":  p.Clan,
+			}).Info("Player nearby")
+		}
+	}
+	for nick := range w.nearbySince {
+		if !nearby[nick] {
+			delete(w.nearbySince, nick)
+		}
+	}
+
+	if policy.PauseAfter > 0 {
+		for nick, since := range w.nearbySince {
+			if now.Sub(since) < time.Duration(policy.PauseAfter)*time.Second {
+				continue
+			}
+			w.log.WithField("nick", nick).Warn("Player stayed nearby, pausing")
+			stateMgr.Pause(time.Duration(policy.PauseDuration)*time.Second, "player "+nick+" nearby")
+			delete(w.nearbySince, nick)
+			break
+		}
+	}
+
+	if policy.CrowdedAt <= 0 || len(w.cfg.Profile.AlternateGrounds) == 0 {
+		return false
+	}
+	if time.Since(w.lastSwitch) < switchCooldown {
+		return false
+	}
+
+	ground := w.cfg.Profile.HuntingGround
+	onGround := 0
+	for _, p := range players {
+		if navigation.GroundContains(ground, behavior.Point{X: p.X, Y: p.Y}) {
+			onGround++
+		}
+	}
+	if hero.MapID != ground.MapID || onGround < policy.CrowdedAt {
+		return false
+	}
+
+	w.log.WithField("players", onGround).Warn("Hunting ground is crowded")
+	w.lastSwitch = now
+	return true
+}
+
+// visible returns players that are not on the ignore list
+func (w *Watch) visible(players []*game.Player) []*game.Player {
+	result := make([]*game.Player, 0, len(players))
+	for _, p := range players {
+		if !w.cfg.Players.Ignores(p.Nick) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// MarkContested flags mobs that another player is fighting
+func MarkContested(mobs []*game.Mob, players []*game.Player, cfg *config.PlayersConfig) {
+	if !cfg.AvoidContested {
+		return
+	}
+
+	for _, m := range mobs {
+		for _, p := range players {
+			if cfg.Ignores(p.Nick) {
+				continue
+			}
+			if p.TargetID == m.ID ||
+				(p.InBattle && distance(p.X, p.Y, m.X, m.Y) <= cfg.ContestRadius) {
+				m.Contested = true
+				break
+			}
+		}
+	}
+}
+
+func distance(x1, y1, x2, y2 float64) float64 {
+	return behavior.Distance(behavior.Point{X: x1, Y: y1}, behavior.Point{X: x2, Y: y2})
+}
@@ -0,0 +1,194 @@
+package quest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/navigation"
+	"github.com/sirupsen/logrus"
+)
+
+// acceptRetry is how long to wait before asking for a quest again after
+// the quest giver's dialog failed
+const acceptRetry = 2 * time.Minute
+
+// Tracker follows the configured quests, counts kills towards them and
+// hands them in when done
+type Tracker struct {
+	gameClient *game.Client
+	navigator  *navigation.Navigator
+	cfg        *config.Config
+	log        *logrus.Logger
+	current    int
+	accepted   bool      // the active quest has been taken
+	retryAt    time.Time // when to ask for the active quest again
+	kills      map[string]int
+}
+
+// NewTracker creates a new quest tracker
+func NewTracker(gameClient *game.Client, navigator *navigation.Navigator, cfg *config.Config, log *logrus.Logger) *Tracker {
+	return &Tracker{
+		gameClient: gameClient,
+		navigator:  navigator,
+		cfg:        cfg,
+		log:        log,
+		kills:      make(map[string]int),
+	}
+}
+
+// Active returns the quest being worked on, nil when all are done
+func (t *Tracker) Active() *config.QuestConfig {
+	if t.current >= len(t.cfg.Profile.Quests) {
+		return nil
+	}
+	return &t.cfg.Profile.Quests[t.current]
+}
+
+// NeedsAccept reports whether the active quest still has to be taken from
+// its quest giver and the last attempt is long enough ago
+func (t *Tracker) NeedsAccept() bool {
+	return t.Active() != nil && !t.accepted && time.Now().After(t.retryAt)
+}
+
+// Accept takes the active quest from its quest giver and walks back to the
+// hunting ground. Quests without an accept dialog count as taken.
+func (t *Tracker) Accept(stateMgr *game.StateManager) error {
+	q := t.Active()
+	if q == nil || t.accepted {
+		return nil
+	}
+	if q.Accept == nil {
+		t.accepted = true
+		return nil
+	}
+
+	t.log.WithField("quest", q.Name).Info("Taking quest")
+	if err := t.talk(*q.Accept, stateMgr); err != nil {
+		t.retryAt = time.Now().Add(acceptRetry)
+		return fmt.Errorf("failed to take %s: %w", q.Name, err)
+	}
+	t.accepted = true
+
+	return t.navigator.GoToHuntingGround(stateMgr)
+}
+
+// RecordKill counts a kill towards the active quest's targets
+func (t *Tracker) RecordKill(mobName string) {
+	q := t.Active()
+	if q == nil || !t.accepted {
+		return
+	}
+	for _, target := range q.Targets {
+		if strings.EqualFold(target.Mob, mobName) {
+			t.kills[target.Mob]++
+			t.log.WithFields(logrus.Fields{
+				"quest":    q.Name,
+				"mob":      target.Mob,
+				"progress": fmt.Sprintf("%d/%d", t.kills[target.Mob], target.Count),
+			}).Debug("Quest kill")
+		}
+	}
+}
+
+// Complete checks the quest log for the active quest's progress. When the
+// quest log can't be read, the kills counted by the bot are used instead.
+func (t *Tracker) Complete() bool {
+	q := t.Active()
+	if q == nil || !t.accepted {
+		return false
+	}
+
+	quests, err := t.gameClient.GetQuests()
+	if err != nil {
+		t.log.WithError(err).Debug("Failed to read quest log, using own kill count")
+		return t.killsComplete(q)
+	}
+
+	for _, logged := range quests {
+		if strings.Contains(strings.ToLower(logged.Name), strings.ToLower(q.Name)) {
+			return logged.Complete()
+		}
+	}
+
+	return t.killsComplete(q)
+}
+
+// TurnIn walks to the quest giver, hands the quest in and takes the next
+// one if configured. It returns false when there are no quests left and
+// the bot should go back to plain hunting. When taking the next quest
+// fails, it stays active and NeedsAccept reports it for a retry.
+func (t *Tracker) TurnIn(stateMgr *game.StateManager) (bool, error) {
+	q := t.Active()
+	if q == nil {
+		return false, nil
+	}
+
+	t.log.WithField("quest", q.Name).Info("Quest complete, turning in")
+	if err := t.talk(q.TurnIn, stateMgr); err != nil {
+		return true, fmt.Errorf("failed to turn in %s: %w", q.Name, err)
+	}
+
+	t.current++
+	t.accepted = false
+	t.kills = make(map[string]int)
+
+	next := t.Active()
+	if next == nil {
+		t.log.Info("All quests finished")
+		return false, t.navigator.GoToHuntingGround(stateMgr)
+	}
+
+	if next.Accept == nil {
+		t.accepted = true
+		return true, t.navigator.GoToHuntingGround(stateMgr)
+	}
+
+	behavior.LongPause()
+	return true, t.Accept(stateMgr)
+}
+
+// talk walks to an NPC and runs the dialog script
+func (t *Tracker) talk(npc config.NPCDialog, stateMgr *game.StateManager) error {
+	if err := t.navigator.FollowRoute(npc.Waypoints, stateMgr); err != nil {
+		return err
+	}
+
+	if hero := stateMgr.GetHero(); npc.MapID != "" && hero.MapID != npc.MapID {
+		return fmt.Errorf("NPC map %s not reached (on %s)", npc.MapID, hero.MapID)
+	}
+
+	if err := t.gameClient.MoveToAndWait(npc.X, npc.Y, game.NPCTolerance, game.NPCWalkTimeout); err != nil {
+		return fmt.Errorf("failed to walk to NPC: %w", err)
+	}
+
+	return t.gameClient.RunDialog(npc.NPCID, npc.Dialog)
+}
+
+// killsComplete checks the bot's own kill counts against the targets
+func (t *Tracker) killsComplete(q *config.QuestConfig) bool {
+	if len(q.Targets) == 0 {
+		return false
+	}
+	for _, target := range q.Targets {
+		if t.kills[target.Mob] < target.Count {
+			return false
+		}
+	}
+	return true
+}
+
+// TargetPriority returns the configured target priority with the mobs of
+// the quest being worked on in front
+func (t *Tracker) TargetPriority() []string {
+	priority := make([]string, 0, len(t.cfg.Combat.TargetPriority))
+	if q := t.Active(); q != nil && t.accepted {
+		for _, target := range q.Targets {
+			priority = append(priority, target.Mob)
+		}
+	}
+	return append(priority, t.cfg.Combat.TargetPriority...)
+}
@@ -0,0 +1,288 @@
+package town
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kamilkurek/margonem-bot/internal/behavior"
+	"github.com/kamilkurek/margonem-bot/internal/config"
+	"github.com/kamilkurek/margonem-bot/internal/game"
+	"github.com/kamilkurek/margonem-bot/internal/loot"
+	"github.com/kamilkurek/margonem-bot/internal/navigation"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retryBase = 2 * time.Minute  // wait after a trip that failed or left the reason standing
+	retryMax  = 30 * time.Minute // longest wait between such trips
+)
+
+// Trip walks to a merchant to sell junk and restock consumables
+type Trip struct {
+	gameClient *game.Client
+	navigator  *navigation.Navigator
+	cfg        *config.Config
+	log        *logrus.Logger
+	junk       *loot.Filter
+	failures   int       // trips in a row that failed or didn't help
+	retryAt    time.Time // no new trip before this
+}
+
+// NewTrip creates a new town trip runner
+func NewTrip(gameClient *game.Client, navigator *navigation.Navigator, cfg *config.Config, log *logrus.Logger) *Trip {
+	return &Trip{
+		gameClient: gameClient,
+		navigator:  navigator,
+		cfg:        cfg,
+		log:        log,
+		junk:       loot.NewFilter(cfg.Profile.Town.Junk),
+	}
+}
+
+// Needed reports whether a town trip is due and why. After a trip that
+// failed or didn't remove its reason (say, the merchant doesn't sell a
+// restock item) the next one waits, longer each time.
+func (t *Trip) Needed(stateMgr *game.StateManager) (bool, string) {
+	if !t.cfg.Profile.Town.Enabled || time.Now().Before(t.retryAt) {
+		return false, ""
+	}
+	return t.due(stateMgr)
+}
+
+// due checks the bags against the town trip triggers
+func (t *Trip) due(stateMgr *game.StateManager) (bool, string) {
+	town := t.cfg.Profile.Town
+
+	inv := stateMgr.GetInventory()
+	if free := inv.FreeSlots(); free >= 0 && free < town.MinFreeSlots {
+		return true, fmt.Sprintf("only %d free bag slots", free)
+	}
+
+	for _, item := range town.Restock {
+		if have := countItem(inv.Items, item.Name); have < item.MinQuantity {
+			return true, fmt.Sprintf("only %d x %s left", have, item.Name)
+		}
+	}
+
+	return false, ""
+}
+
+// Run walks to the merchant, sells junk, buys consumables and walks back
+func (t *Trip) Run(stateMgr *game.StateManager) error {
+	err := t.run(stateMgr)
+
+	still, reason := t.due(stateMgr)
+	if err == nil && !still {
+		t.failures = 0
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("trip did not help: %s", reason)
+	}
+
+	wait := behavior.Backoff(retryBase, 2.0, retryMax, t.failures)
+	t.failures++
+	t.retryAt = time.Now().Add(wait)
+	t.log.WithField("retryIn", wait).Warn("Holding off town trips")
+	return err
+}
+
+// run makes the trip itself
+func (t *Trip) run(stateMgr *game.StateManager) error {
+	town := t.cfg.Profile.Town
+	t.log.Info("Going to town...")
+
+	if err := t.navigator.FollowRoute(town.Waypoints, stateMgr); err != nil {
+		return fmt.Errorf("failed to reach town: %w", err)
+	}
+
+	hero := stateMgr.GetHero()
+	if hero.MapID != town.Merchant.MapID {
+		return fmt.Errorf("merchant map %s not reached (on %s)", town.Merchant.MapID, hero.MapID)
+	}
+
+	if err := t.gameClient.MoveToAndWait(town.Merchant.X, town.Merchant.Y, game.NPCTolerance, game.NPCWalkTimeout); err != nil {
+		return fmt.Errorf("failed to walk to merchant: %w", err)
+	}
+
+	if err := t.gameClient.OpenShop(town.Merchant.NPCID); err != nil {
+		return err
+	}
+
+	goldStart, _ := t.currentGold()
+	if err := t.sellJunk(stateMgr); err != nil {
+		t.log.WithError(err).Warn("Selling failed")
+	}
+	goldSold, _ := t.currentGold()
+
+	if err := t.restock(stateMgr); err != nil {
+		t.log.WithError(err).Warn("Restocking failed")
+	}
+	goldEnd, _ := t.currentGold()
+
+	earned, spent := 0, 0
+	if goldStart >= 0 && goldSold > goldStart {
+		earned = goldSold - goldStart
+	}
+	if goldSold >= 0 && goldEnd >= 0 && goldEnd < goldSold {
+		spent = goldSold - goldEnd
+	}
+
+	if err := t.gameClient.CloseShop(); err != nil {
+		t.log.WithError(err).Debug("Failed to close shop")
+	}
+	t.refreshInventory(stateMgr)
+
+	stateMgr.RecordTownTrip(earned, spent)
+	t.log.WithFields(logrus.Fields{
+		"earned": earned,
+		"spent":  spent,
+	}).Info("Town trip finished")
+
+	behavior.LongPause()
+	return t.navigator.GoToHuntingGround(stateMgr)
+}
+
+// sellJunk sells every bag item matched by the junk filter
+func (t *Trip) sellJunk(stateMgr *game.StateManager) error {
+	// An empty junk filter would sell the whole bag
+	if t.junk.Empty() {
+		t.log.Debug("No junk rules configured, not selling")
+		return nil
+	}
+
+	t.refreshInventory(stateMgr)
+
+	var ids []string
+	for _, item := range stateMgr.GetInventory().Items {
+		// Never sell what the trip is about to buy back
+		if t.restocked(item) {
+			continue
+		}
+		if ok, _ := t.junk.Matches(item); !ok {
+			continue
+		}
+		t.log.WithFields(logrus.Fields{
+			"item":  item.Name,
+			"value": item.Value,
+		}).Info("Selling item")
+		ids = append(ids, item.ID)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	behavior.RandomPause()
+	return t.gameClient.SellItems(ids)
+}
+
+// restock buys configured consumables up to their target quantities.
+// Consumables stack, so a purchase grows the stack already carried and
+// only needs a free bag slot when the hero has none of the item left.
+func (t *Trip) restock(stateMgr *game.StateManager) error {
+	t.refreshInventory(stateMgr)
+
+	offers, err := t.gameClient.GetShopItems()
+	if err != nil {
+		return err
+	}
+
+	inv := stateMgr.GetInventory()
+	free := inv.FreeSlots()
+	for _, want := range t.cfg.Profile.Town.Restock {
+		have := countItem(inv.Items, want.Name)
+		need := want.Quantity - have
+		if need <= 0 {
+			continue
+		}
+		slots := 0
+		if have == 0 {
+			slots = 1
+		}
+		if free >= 0 && slots > free {
+			t.log.WithField("item", want.Name).Info("No free bag slot to restock item")
+			continue
+		}
+
+		offer := findItem(offers, want.Name)
+		if offer == nil {
+			t.log.WithField("item", want.Name).Warn("Merchant does not sell item")
+			continue
+		}
+
+		t.log.WithFields(logrus.Fields{
+			"item":     offer.Name,
+			"quantity": need,
+		}).Info("Buying item")
+
+		behavior.RandomPause()
+		if err := t.gameClient.BuyItem(offer.ID, need); err != nil {
+			return fmt.Errorf("failed to buy %s: %w", want.Name, err)
+		}
+		if free >= 0 {
+			free -= slots
+		}
+	}
+
+	return nil
+}
+
+// restocked reports whether an item is one of the restock consumables
+func (t *Trip) restocked(item *game.Item) bool {
+	for _, want := range t.cfg.Profile.Town.Restock {
+		if matchesName(item, want.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshInventory re-reads the bags into the inventory cache
+func (t *Trip) refreshInventory(stateMgr *game.StateManager) {
+	// Give the server a moment to apply the last trade
+	time.Sleep(1 * time.Second)
+
+	inv, err := t.gameClient.GetInventory()
+	if err != nil {
+		t.log.WithError(err).Debug("Failed to refresh inventory")
+		return
+	}
+	stateMgr.UpdateInventory(inv)
+}
+
+// currentGold reads the hero's gold directly from the game, -1 if unavailable
+func (t *Trip) currentGold() (int, error) {
+	hero, err := t.gameClient.GetHeroState()
+	if err != nil {
+		t.log.WithError(err).Debug("Failed to read gold")
+		return -1, err
+	}
+	return hero.Gold, nil
+}
+
+// countItem sums the quantity of bag items whose name contains name
+func countItem(items []*game.Item, name string) int {
+	total := 0
+	for _, item := range items {
+		if matchesName(item, name) {
+			total += item.Quantity
+		}
+	}
+	return total
+}
+
+// findItem returns the first item whose name contains name
+func findItem(items []*game.Item, name string) *game.Item {
+	for _, item := range items {
+		if matchesName(item, name) {
+			return item
+		}
+	}
+	return nil
+}
+
+func matchesName(item *game.Item, name string) bool {
+	return strings.Contains(strings.ToLower(item.Name), strings.ToLower(name))
+}